@@ -25,9 +25,12 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/vhive-serverless/loader/pkg/generator"
@@ -51,9 +54,12 @@ var (
 	configPath    = flag.String("config", "cmd/config_knative_trace.json", "Path to loader configuration file")
 	failurePath   = flag.String("failureConfig", "cmd/failure.json", "Path to the failure configuration file")
 	verbosity     = flag.String("verbosity", "info", "Logging verbosity - choose from [info, debug, trace]")
-	iatGeneration = flag.Bool("iatGeneration", false, "Generate IATs only or run invocations as well")
-	iatFromFile   = flag.Bool("generated", false, "True if iats were already generated")
+	iatGeneration = flag.Bool("iatGeneration", false, "Generate the specification bundle only or run invocations as well")
+	iatFromFile   = flag.Bool("generated", false, "True if the specification bundle was already generated")
 	dryRun        = flag.Bool("dryRun", false, "Dry run mode - do not deploy functions or generate invocations")
+
+	validateSpecification = flag.String("validateSpecification", "", "Write a report of the fidelity of the generated specification to the given JSON file and exit")
+	simulateColdStarts    = flag.String("simulateColdStarts", "", "Simulate the cold starts of the generated specification under the keep-alive policies, write them to the given CSV file and exit")
 )
 
 func init() {
@@ -86,9 +92,24 @@ func main() {
 		}
 		defer shutdown()
 	}
-	if cfg.ExperimentDuration < 1 {
+	if cfg.ExperimentDuration < 1 && len(cfg.RpsStages) == 0 {
 		log.Fatal("Runtime duration should be longer, at least a minute.")
 	}
+	if cfg.TimeScale < 0 || (cfg.TimeScale > 0 && cfg.TracePath == "RPS") {
+		log.Fatal("Time scale should be positive and is supported only in trace and closed-loop modes.")
+	}
+	if cfg.LazySpecification && (cfg.TracePath == "RPS" || cfg.TracePath == "ClosedLoop" || cfg.DAGMode) {
+		log.Fatal("Lazy generation of the specifications is supported only in trace mode without DAGs.")
+	}
+	if cfg.TraceFormat == "timestamps" && cfg.Granularity == "second" {
+		log.Fatal("Invocation timestamp traces are supported only with minute granularity.")
+	}
+	if len(cfg.RpsStages) > 0 && cfg.WarmupDuration > 0 {
+		log.Fatal("WarmupDuration is not supported with RpsStages, whose first stage can serve as a warm-up.")
+	}
+	if cfg.TraceFormat == "timestamps" && cfg.DAGMode {
+		log.Fatal("Invocation timestamp traces are not supported in DAG mode.")
+	}
 
 	supportedPlatforms := []string{
 		"Knative",
@@ -106,13 +127,35 @@ func main() {
 		common.CheckCPULimit(cfg.CPULimit)
 	}
 
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	cancelOnSignal(cancel)
+
 	if cfg.TracePath == "RPS" {
-		runRPSMode(&cfg, *iatFromFile, *iatGeneration)
+		runRPSMode(ctx, &cfg, *iatFromFile, *iatGeneration)
+	} else if cfg.TracePath == "ClosedLoop" {
+		runClosedLoopMode(ctx, &cfg)
 	} else {
-		runTraceMode(&cfg, *iatFromFile, *iatGeneration)
+		runTraceMode(ctx, &cfg, *iatFromFile, *iatGeneration)
 	}
 }
 
+// cancelOnSignal cancels the experiment on SIGINT or SIGTERM, so that the results collected so far get written and
+// the deployed functions get cleaned up. A second signal terminates the loader immediately.
+func cancelOnSignal(cancel context.CancelCauseFunc) {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		sig := <-signals
+		log.Warnf("Received %s. Stopping the experiment...", sig)
+		cancel(fmt.Errorf("received signal %s", sig))
+
+		sig = <-signals
+		log.Fatalf("Received %s again. Exiting without cleanup.", sig)
+	}()
+}
+
 func determineDurationToParse(runtimeDuration int, warmupDuration int) int {
 	result := 0
 
@@ -137,6 +180,26 @@ func parseIATDistribution(cfg *config.LoaderConfiguration) (common.IatDistributi
 		return common.Uniform, true
 	case "equidistant":
 		return common.Equidistant, false
+	case "weibull":
+		return common.Weibull, false
+	case "weibull_shift":
+		return common.Weibull, true
+	case "pareto":
+		return common.Pareto, false
+	case "pareto_shift":
+		return common.Pareto, true
+	case "lognormal":
+		return common.Lognormal, false
+	case "lognormal_shift":
+		return common.Lognormal, true
+	case "gamma":
+		return common.Gamma, false
+	case "gamma_shift":
+		return common.Gamma, true
+	case "mmpp":
+		return common.MMPP, false
+	case "mmpp_shift":
+		return common.MMPP, true
 	default:
 		log.Fatal("Unsupported IAT distribution.")
 	}
@@ -172,13 +235,18 @@ func parseTraceGranularity(cfg *config.LoaderConfiguration) common.TraceGranular
 	return common.MinuteGranularity
 }
 
-func runTraceMode(cfg *config.LoaderConfiguration, readIATFromFile bool, writeIATsToFile bool) {
+func runTraceMode(ctx context.Context, cfg *config.LoaderConfiguration, readIATFromFile bool, writeIATsToFile bool) {
+	if cfg.LazySpecification && (readIATFromFile || writeIATsToFile) {
+		log.Fatal("Lazily generated specifications cannot be read from or written to a specification bundle.")
+	}
+
 	durationToParse := determineDurationToParse(cfg.ExperimentDuration, cfg.WarmupDuration)
 	yamlPath := parseYAMLSpecification(cfg)
 
-	// Azure trace parsing
-	traceParser := trace.NewAzureParser(cfg.TracePath, durationToParse)
+	// Trace parsing
+	traceParser := trace.NewTraceParser(cfg, durationToParse)
 	functions := traceParser.Parse()
+	functions = trace.FilterFunctions(functions, cfg)
 
 	// Dirigent metadata parsing
 	dirigentMetadataParser := trace.NewDirigentMetadataParser(cfg.TracePath, functions, yamlPath, cfg.Platform)
@@ -189,6 +257,12 @@ func runTraceMode(cfg *config.LoaderConfiguration, readIATFromFile bool, writeIA
 		fmt.Printf("\t%s\n", function.Name)
 	}
 
+	var workflows []*common.Workflow
+	if cfg.DAGMode && cfg.DAGWorkflowPath != "" {
+		workflowParser := trace.NewWorkflowParser(cfg.DAGWorkflowPath, functions)
+		workflows = workflowParser.Parse()
+	}
+
 	iatType, shiftIAT := parseIATDistribution(cfg)
 
 	experimentDriver := driver.NewDriver(&config.Configuration{
@@ -204,6 +278,7 @@ func runTraceMode(cfg *config.LoaderConfiguration, readIATFromFile bool, writeIA
 		TestMode: false,
 
 		Functions: functions,
+		Workflows: workflows,
 	})
 
 	// Skip experiments execution during dry run mode
@@ -215,28 +290,47 @@ func runTraceMode(cfg *config.LoaderConfiguration, readIATFromFile bool, writeIA
 
 	experimentDriver.GenerateSpecification()
 	experimentDriver.ReadOrWriteFileSpecification(writeIATsToFile, readIATFromFile)
-	experimentDriver.RunExperiment()
+	if *validateSpecification != "" {
+		experimentDriver.ValidateSpecification(*validateSpecification)
+	}
+	if *simulateColdStarts != "" {
+		experimentDriver.SimulateColdStarts(*simulateColdStarts)
+	}
+	experimentDriver.RunExperiment(ctx)
 }
 
-func runRPSMode(cfg *config.LoaderConfiguration, readIATFromFile bool, writeIATsToFile bool) {
+func runRPSMode(ctx context.Context, cfg *config.LoaderConfiguration, readIATFromFile bool, writeIATsToFile bool) {
 	experimentDuration := determineDurationToParse(cfg.ExperimentDuration, cfg.WarmupDuration)
 
-	rpsTarget := cfg.RpsTarget
-	coldStartPercentage := cfg.RpsColdStartRatioPercentage
+	var functions []*common.Function
+	var stages []common.LoadStage
+	if len(cfg.RpsStages) > 0 {
+		load := generator.GenerateStagedRPSLoad(cfg.RpsStages, cfg.RpsCooldownSeconds)
+
+		experimentDuration = load.Duration
+		functions = generator.CreateRPSFunctions(cfg, load.WarmFunction, load.WarmFunctionCount, load.ColdFunctions, load.ColdFunctionCount)
+		stages = load.Stages
+	} else {
+		rpsTarget := cfg.RpsTarget
+		coldStartPercentage := cfg.RpsColdStartRatioPercentage
+
+		warmStartRPS := rpsTarget * (100 - coldStartPercentage) / 100
+		coldStartRPS := rpsTarget * coldStartPercentage / 100
 
-	warmStartRPS := rpsTarget * (100 - coldStartPercentage) / 100
-	coldStartRPS := rpsTarget * coldStartPercentage / 100
+		warmFunction, warmStartCount := generator.GenerateWarmStartFunction(experimentDuration, warmStartRPS)
+		coldFunctions, coldStartCount := generator.GenerateColdStartFunctions(experimentDuration, coldStartRPS, cfg.RpsCooldownSeconds)
 
-	warmFunction, warmStartCount := generator.GenerateWarmStartFunction(experimentDuration, warmStartRPS)
-	coldFunctions, coldStartCount := generator.GenerateColdStartFunctions(experimentDuration, coldStartRPS, cfg.RpsCooldownSeconds)
+		functions = generator.CreateRPSFunctions(cfg, warmFunction, warmStartCount, coldFunctions, coldStartCount)
+	}
 
 	experimentDriver := driver.NewDriver(&config.Configuration{
 		LoaderConfiguration: cfg,
 		TraceDuration:       experimentDuration,
+		LoadStages:          stages,
 
 		YAMLPath: parseYAMLSpecification(cfg),
 
-		Functions: generator.CreateRPSFunctions(cfg, warmFunction, warmStartCount, coldFunctions, coldStartCount),
+		Functions: functions,
 	})
 
 	// Skip experiments execution during dry run mode
@@ -245,5 +339,24 @@ func runRPSMode(cfg *config.LoaderConfiguration, readIATFromFile bool, writeIATs
 	}
 
 	experimentDriver.ReadOrWriteFileSpecification(writeIATsToFile, readIATFromFile)
-	experimentDriver.RunExperiment()
+	experimentDriver.RunExperiment(ctx)
+}
+
+func runClosedLoopMode(ctx context.Context, cfg *config.LoaderConfiguration) {
+	experimentDriver := driver.NewDriver(&config.Configuration{
+		LoaderConfiguration: cfg,
+		TraceDuration:       determineDurationToParse(cfg.ExperimentDuration, cfg.WarmupDuration),
+		ClosedLoop:          true,
+
+		YAMLPath: parseYAMLSpecification(cfg),
+
+		Functions: generator.CreateClosedLoopFunctions(cfg),
+	})
+
+	// Skip experiments execution during dry run mode
+	if *dryRun {
+		return
+	}
+
+	experimentDriver.RunExperiment(ctx)
 }
@@ -54,6 +54,11 @@ const (
 	Exponential IatDistribution = iota
 	Uniform
 	Equidistant
+	Weibull
+	Pareto
+	Lognormal
+	Gamma
+	MMPP
 )
 
 type TraceGranularity int
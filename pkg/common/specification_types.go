@@ -45,3 +45,17 @@ type FunctionSpecification struct {
 	RawDuration          ProbabilisticDuration     `json:"RawDuration"`
 	RuntimeSpecification RuntimeSpecificationArray `json:"RuntimeSpecification"`
 }
+
+// LoadStage describes the boundaries of one stage of a multi-stage load profile. Start and end times are relative to
+// the beginning of the experiment until the experiment starts, after which they are Unix timestamps.
+type LoadStage struct {
+	Stage                    int     `csv:"stage"`
+	Shape                    string  `csv:"shape"`
+	StartRPS                 float64 `csv:"startRPS"`
+	EndRPS                   float64 `csv:"endRPS"`
+	ColdStartRatioPercentage float64 `csv:"coldStartRatioPercentage"`
+
+	// Measurements in microseconds
+	StartTime int64 `csv:"startTime"`
+	EndTime   int64 `csv:"endTime"`
+}
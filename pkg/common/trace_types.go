@@ -24,8 +24,6 @@
 
 package common
 
-import "container/list"
-
 type FunctionInvocationStats struct {
 	HashOwner    string
 	HashApp      string
@@ -104,11 +102,6 @@ type Function struct {
 	CPULimitsMilli    int
 
 	Specification *FunctionSpecification
-}
-
-type Node struct {
-	Function *Function
-	Branches []*list.List
-	Depth    int
-	DAG      string
+	// ReplaySpecification is set for invocation-level traces, whose Specification holds the exact IATs and runtimes
+	ReplaySpecification bool
 }
@@ -118,6 +118,12 @@ func Hash(s string) uint64 {
 	return h.Sum64()
 }
 
+// NewFunctionRand returns a random generator determined only by the seed of the experiment and a key of the function,
+// such as its HashFunction, so that the name and the properties of the function stay the same across runs
+func NewFunctionRand(seed int64, key string) *rand.Rand {
+	return rand.New(rand.NewSource(seed ^ int64(Hash(key))))
+}
+
 func SumNumberOfInvocations(withWarmup bool, totalDuration int, functions []*Function) int {
 	result := 0
 
@@ -135,15 +141,3 @@ func SumNumberOfInvocations(withWarmup bool, totalDuration int, functions []*Fun
 
 	return result
 }
-
-func GetName(function *Function) int {
-	parts := strings.Split(function.Name, "-")
-	if parts[0] == "test" {
-		return 0
-	}
-	functionId, err := strconv.Atoi(parts[2])
-	if err != nil {
-		log.Fatal(err)
-	}
-	return functionId
-}
\ No newline at end of file
@@ -0,0 +1,127 @@
+package common
+
+import (
+	"fmt"
+)
+
+// Workflow is a directed acyclic graph of function invocations. An invocation of the workflow starts at its root,
+// and each node is invoked once all of its predecessors have completed.
+type Workflow struct {
+	Name  string
+	Root  *WorkflowNode
+	Nodes []*WorkflowNode
+}
+
+// WorkflowNode is one function invocation within a workflow. A node with more than one predecessor is a join.
+type WorkflowNode struct {
+	Name     string
+	Function *Function
+	Depth    int
+
+	Successors   []*WorkflowEdge
+	Predecessors []*WorkflowEdge
+}
+
+// WorkflowEdge connects two nodes of a workflow. Once the predecessor completes successfully, the successor is
+// invoked with the probability of the edge.
+type WorkflowEdge struct {
+	From        *WorkflowNode
+	To          *WorkflowNode
+	Probability float64
+}
+
+func NewWorkflow(name string) *Workflow {
+	return &Workflow{Name: name}
+}
+
+// NewSingleFunctionWorkflow wraps a function into a workflow with a single node
+func NewSingleFunctionWorkflow(function *Function) *Workflow {
+	workflow := NewWorkflow("")
+	workflow.AddNode(function.Name, function)
+
+	return workflow
+}
+
+// AddNode adds a node to the workflow. The first node added becomes the root of the workflow.
+func (w *Workflow) AddNode(name string, function *Function) *WorkflowNode {
+	node := &WorkflowNode{Name: name, Function: function}
+
+	w.Nodes = append(w.Nodes, node)
+	if w.Root == nil {
+		w.Root = node
+	}
+
+	return node
+}
+
+func (w *Workflow) AddEdge(from *WorkflowNode, to *WorkflowNode, probability float64) {
+	edge := &WorkflowEdge{From: from, To: to, Probability: probability}
+
+	from.Successors = append(from.Successors, edge)
+	to.Predecessors = append(to.Predecessors, edge)
+}
+
+// Finalize checks that the workflow is a connected DAG with a single root, makes that node the root of the workflow
+// and sets the depth of every node to the length of the longest path from the root.
+func (w *Workflow) Finalize() error {
+	var roots []*WorkflowNode
+	unresolved := make(map[*WorkflowNode]int)
+
+	for _, node := range w.Nodes {
+		unresolved[node] = len(node.Predecessors)
+		if len(node.Predecessors) == 0 {
+			roots = append(roots, node)
+		}
+
+		for _, edge := range node.Successors {
+			if edge.Probability <= 0 || edge.Probability > 1 {
+				return fmt.Errorf("workflow %s: probability of edge %s -> %s should be in (0, 1]", w.Name, edge.From.Name, edge.To.Name)
+			}
+		}
+	}
+
+	if len(roots) != 1 {
+		return fmt.Errorf("workflow %s should have exactly one root, got %d", w.Name, len(roots))
+	}
+	w.Root = roots[0]
+	w.Root.Depth = 0
+
+	// Kahn's algorithm - nodes that are never resolved are part of a cycle
+	visited := 0
+	queue := []*WorkflowNode{w.Root}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		visited++
+
+		for _, edge := range node.Successors {
+			edge.To.Depth = max(edge.To.Depth, node.Depth+1)
+
+			unresolved[edge.To]--
+			if unresolved[edge.To] == 0 {
+				queue = append(queue, edge.To)
+			}
+		}
+	}
+
+	if visited != len(w.Nodes) {
+		return fmt.Errorf("workflow %s contains a cycle", w.Name)
+	}
+
+	return nil
+}
+
+// Shape returns the largest number of nodes at the same depth and the number of levels of the workflow
+func (w *Workflow) Shape() (int, int) {
+	nodesPerDepth := make(map[int]int)
+	width, depth := 0, 0
+
+	for _, node := range w.Nodes {
+		nodesPerDepth[node.Depth]++
+
+		width = max(width, nodesPerDepth[node.Depth])
+		depth = max(depth, node.Depth+1)
+	}
+
+	return width, depth
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/vhive-serverless/loader/pkg/common"
 )
 
@@ -16,8 +18,23 @@ type Configuration struct {
 
 	YAMLPath string
 	TestMode bool
+	// ClosedLoop replaces the trace-driven dispatching with a fixed number of virtual users per function
+	ClosedLoop bool
+	// LoadStages are the stages of a multi-stage load profile, written next to the experiment output
+	LoadStages []common.LoadStage
 
 	Functions []*common.Function
+	// Workflows replace the randomly generated DAGs in DAG mode
+	Workflows []*common.Workflow
+}
+
+// TraceMinute returns the wall-clock duration of one minute of the trace once the time scale has been applied
+func (c *Configuration) TraceMinute() time.Duration {
+	if c.LoaderConfiguration.TimeScale > 0 {
+		return time.Duration(float64(time.Minute) / c.LoaderConfiguration.TimeScale)
+	}
+
+	return time.Minute
 }
 
 func (c *Configuration) WithWarmup() bool {
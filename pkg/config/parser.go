@@ -39,6 +39,28 @@ type FailureConfiguration struct {
 	FailNode      string `json:"FailNode"`
 }
 
+// PayloadConfiguration holds the payloads of the invocations, either the same for all the functions or overridden for
+// some functions by their HashFunction or name
+type PayloadConfiguration struct {
+	Default   PayloadSpecification            `json:"Default"`
+	Functions map[string]PayloadSpecification `json:"Functions"`
+}
+
+type PayloadSpecification struct {
+	// SizeBytes is the size of the request payload, or its mean for the exponential distribution and its median for the
+	// lognormal distribution
+	SizeBytes        int     `json:"SizeBytes"`
+	SizeDistribution string  `json:"SizeDistribution"`
+	MinSizeBytes     int     `json:"MinSizeBytes"`
+	MaxSizeBytes     int     `json:"MaxSizeBytes"`
+	SizeSigma        float64 `json:"SizeSigma"`
+	// Path of a file sent as the payload, or of a directory whose files are sent, overriding the size
+	Path string `json:"Path"`
+
+	// ResponseSizeBytes is the size of the response requested from the function, which echoes the request if zero
+	ResponseSizeBytes int `json:"ResponseSizeBytes"`
+}
+
 type LoaderConfiguration struct {
 	Seed int64 `json:"Seed"`
 
@@ -54,22 +76,66 @@ type LoaderConfiguration struct {
 	AsyncResponseURL      string `json:"AsyncResponseURL"`
 	AsyncWaitToCollectMin int    `json:"AsyncWaitToCollectMin"`
 
-	RpsTarget                   float64 `json:"RpsTarget"`
-	RpsColdStartRatioPercentage float64 `json:"RpsColdStartRatioPercentage"`
-	RpsCooldownSeconds          int     `json:"RpsCooldownSeconds"`
-	RpsImage                    string  `json:"RpsImage"`
-	RpsRuntimeMs                int     `json:"RpsRuntimeMs"`
-	RpsMemoryMB                 int     `json:"RpsMemoryMB"`
-	RpsIterationMultiplier      int     `json:"RpsIterationMultiplier"`
-
-	TracePath          string `json:"TracePath"`
-	Granularity        string `json:"Granularity"`
-	OutputPathPrefix   string `json:"OutputPathPrefix"`
-	IATDistribution    string `json:"IATDistribution"`
-	CPULimit           string `json:"CPULimit"`
-	ExperimentDuration int    `json:"ExperimentDuration"`
-	WarmupDuration     int    `json:"WarmupDuration"`
-	PrepullMode        string `json:"PrepullMode"`
+	RpsTarget                   float64    `json:"RpsTarget"`
+	RpsColdStartRatioPercentage float64    `json:"RpsColdStartRatioPercentage"`
+	RpsCooldownSeconds          int        `json:"RpsCooldownSeconds"`
+	RpsImage                    string     `json:"RpsImage"`
+	RpsRuntimeMs                int        `json:"RpsRuntimeMs"`
+	RpsMemoryMB                 int        `json:"RpsMemoryMB"`
+	RpsIterationMultiplier      int        `json:"RpsIterationMultiplier"`
+	RpsStages                   []RPSStage `json:"RpsStages"`
+
+	ClosedLoopFunctions             int    `json:"ClosedLoopFunctions"`
+	ClosedLoopUsers                 int    `json:"ClosedLoopUsers"`
+	ClosedLoopThinkTimeMs           int    `json:"ClosedLoopThinkTimeMs"`
+	ClosedLoopThinkTimeDistribution string `json:"ClosedLoopThinkTimeDistribution"`
+
+	TracePath          string            `json:"TracePath"`
+	TraceFormat        string            `json:"TraceFormat"`
+	TraceFile          string            `json:"TraceFile"`
+	TraceColumnMapping map[string]string `json:"TraceColumnMapping"`
+	LazySpecification  bool              `json:"LazySpecification"`
+	Granularity        string            `json:"Granularity"`
+	OutputPathPrefix   string            `json:"OutputPathPrefix"`
+	IATDistribution    string            `json:"IATDistribution"`
+	CPULimit           string            `json:"CPULimit"`
+	ExperimentDuration int               `json:"ExperimentDuration"`
+	WarmupDuration     int               `json:"WarmupDuration"`
+	TimeScale          float64           `json:"TimeScale"`
+	ScaleRuntimes      bool              `json:"ScaleRuntimes"`
+	PrepullMode        string            `json:"PrepullMode"`
+	InvocationWorkers  int               `json:"InvocationWorkers"`
+
+	TraceInvocationsFile string `json:"TraceInvocationsFile"`
+	TraceDurationsFile   string `json:"TraceDurationsFile"`
+	TraceMemoryFile      string `json:"TraceMemoryFile"`
+
+	IncludeTriggers          []string  `json:"IncludeTriggers"`
+	ExcludeTriggers          []string  `json:"ExcludeTriggers"`
+	IncludeOwners            []string  `json:"IncludeOwners"`
+	IncludeApps              []string  `json:"IncludeApps"`
+	TopNFunctions            int       `json:"TopNFunctions"`
+	InvocationsPerMinuteBand []float64 `json:"InvocationsPerMinuteBand"`
+	RuntimePercentileBand    []float64 `json:"RuntimePercentileBand"`
+
+	SpecificationBundlePath string `json:"SpecificationBundlePath"`
+
+	ShutdownGracePeriodSeconds int `json:"ShutdownGracePeriodSeconds"`
+
+	IATWeibullShape    float64   `json:"IATWeibullShape"`
+	IATParetoShape     float64   `json:"IATParetoShape"`
+	IATLognormalSigma  float64   `json:"IATLognormalSigma"`
+	IATGammaShape      float64   `json:"IATGammaShape"`
+	IATMMPPRates       []float64 `json:"IATMMPPRates"`
+	IATMMPPSwitchRates []float64 `json:"IATMMPPSwitchRates"`
+
+	ExecutionSpecSampler     string  `json:"ExecutionSpecSampler"`
+	RuntimeMemoryCorrelation float64 `json:"RuntimeMemoryCorrelation"`
+
+	SimulatorPolicies            []string `json:"SimulatorPolicies"`
+	SimulatorKeepAliveSeconds    int      `json:"SimulatorKeepAliveSeconds"`
+	SimulatorTargetConcurrency   int      `json:"SimulatorTargetConcurrency"`
+	SimulatorStableWindowSeconds int      `json:"SimulatorStableWindowSeconds"`
 
 	IsPartiallyPanic            bool   `json:"IsPartiallyPanic"`
 	EnableZipkinTracing         bool   `json:"EnableZipkinTracing"`
@@ -77,13 +143,59 @@ type LoaderConfiguration struct {
 	MetricScrapingPeriodSeconds int    `json:"MetricScrapingPeriodSeconds"`
 	AutoscalingMetric           string `json:"AutoscalingMetric"`
 
-	GRPCConnectionTimeoutSeconds int  `json:"GRPCConnectionTimeoutSeconds"`
-	GRPCFunctionTimeoutSeconds   int  `json:"GRPCFunctionTimeoutSeconds"`
-	DAGMode                      bool `json:"DAGMode"`
-	EnableDAGDataset             bool `json:"EnableDAGDataset"`
-	Width                        int  `json:"Width"`
-	Depth                        int  `json:"Depth"`
-	VSwarm                       bool `json:"VSwarm"`
+	EnableRuntimeAssertions             bool    `json:"EnableRuntimeAssertions"`
+	RequestedVsIssuedWarnThreshold      float64 `json:"RequestedVsIssuedWarnThreshold"`
+	RequestedVsIssuedTerminateThreshold float64 `json:"RequestedVsIssuedTerminateThreshold"`
+	FailedWarnThreshold                 float64 `json:"FailedWarnThreshold"`
+	FailedTerminateThreshold            float64 `json:"FailedTerminateThreshold"`
+
+	GRPCConnectionTimeoutSeconds int    `json:"GRPCConnectionTimeoutSeconds"`
+	GRPCFunctionTimeoutSeconds   int    `json:"GRPCFunctionTimeoutSeconds"`
+	DAGMode                      bool   `json:"DAGMode"`
+	EnableDAGDataset             bool   `json:"EnableDAGDataset"`
+	DAGWorkflowPath              string `json:"DAGWorkflowPath"`
+	Width                        int    `json:"Width"`
+	Depth                        int    `json:"Depth"`
+	VSwarm                       bool   `json:"VSwarm"`
+
+	GRPCConnectionPoolSize           int  `json:"GRPCConnectionPoolSize"`
+	GRPCConnectionIdleTimeoutSeconds int  `json:"GRPCConnectionIdleTimeoutSeconds"`
+	GRPCFreshConnectionPerInvocation bool `json:"GRPCFreshConnectionPerInvocation"`
+
+	PayloadConfigPath string `json:"PayloadConfigPath"`
+
+	TLSEnabled            bool   `json:"TLSEnabled"`
+	TLSCACertPath         string `json:"TLSCACertPath"`
+	TLSClientCertPath     string `json:"TLSClientCertPath"`
+	TLSClientKeyPath      string `json:"TLSClientKeyPath"`
+	TLSServerName         string `json:"TLSServerName"`
+	TLSInsecureSkipVerify bool   `json:"TLSInsecureSkipVerify"`
+
+	InvocationRetries           int               `json:"InvocationRetries"`
+	InvocationRetryBackoffMs    int               `json:"InvocationRetryBackoffMs"`
+	InvocationRetryMaxBackoffMs int               `json:"InvocationRetryMaxBackoffMs"`
+	InvocationRetryJitter       float64           `json:"InvocationRetryJitter"`
+	ClientRateLimitRPS          float64           `json:"ClientRateLimitRPS"`
+	ClientRateLimitBurst        int               `json:"ClientRateLimitBurst"`
+	MaxInFlightPerFunction      int               `json:"MaxInFlightPerFunction"`
+	InvocationHeaders           map[string]string `json:"InvocationHeaders"`
+	FaultDropPercentage         float64           `json:"FaultDropPercentage"`
+	FaultDelayPercentage        float64           `json:"FaultDelayPercentage"`
+	FaultDelayMs                int               `json:"FaultDelayMs"`
+}
+
+// RPSStage is one stage of a multi-stage load profile in RPS mode
+type RPSStage struct {
+	DurationMinutes          int     `json:"DurationMinutes"`
+	Shape                    string  `json:"Shape"`
+	StartRPS                 float64 `json:"StartRPS"`
+	EndRPS                   float64 `json:"EndRPS"`
+	ColdStartRatioPercentage float64 `json:"ColdStartRatioPercentage"`
+
+	// Steps is the number of equally long levels between StartRPS and EndRPS of a step stage
+	Steps int `json:"Steps"`
+	// SpikeDurationSeconds is the length of the EndRPS burst in the middle of a spike stage
+	SpikeDurationSeconds int `json:"SpikeDurationSeconds"`
 }
 
 func ReadConfigurationFile(path string) LoaderConfiguration {
@@ -118,3 +230,18 @@ func ReadFailureConfiguration(path string) *FailureConfiguration {
 
 	return &config
 }
+
+func ReadPayloadConfiguration(path string) *PayloadConfiguration {
+	byteValue, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("Failed to read the payload configuration - %v", err)
+	}
+
+	var config PayloadConfiguration
+	err = json.Unmarshal(byteValue, &config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return &config
+}
@@ -1,33 +1,38 @@
 package clients
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	log "github.com/sirupsen/logrus"
 	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
 	mc "github.com/vhive-serverless/loader/pkg/metric"
 	"io"
-	"sync"
+	"net/http"
 )
 
 type awsLambdaInvoker struct {
-	announceDoneExe *sync.WaitGroup
+	client *http.Client
 }
 
-func newAWSLambdaInvoker(announceDoneExe *sync.WaitGroup) *awsLambdaInvoker {
+func newAWSLambdaInvoker(cfg *config.LoaderConfiguration) *awsLambdaInvoker {
+	if cfg.PayloadConfigPath != "" {
+		log.Warn("Payloads are not sent to AWS Lambda functions.")
+	}
+
 	return &awsLambdaInvoker{
-		announceDoneExe: announceDoneExe,
+		client: newPlatformHTTPClient(NewTLSConfig(cfg)),
 	}
 }
 
-func (i *awsLambdaInvoker) Invoke(function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *mc.ExecutionRecord) {
+func (i *awsLambdaInvoker) Invoke(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *mc.ExecutionRecord) {
 	log.Tracef("(Invoke)\t %s: %d[ms], %d[MiB]", function.Name, runtimeSpec.Runtime, runtimeSpec.Memory)
 
 	dataString := fmt.Sprintf(`{"RuntimeInMilliSec": %d, "MemoryInMebiBytes": %d}`, runtimeSpec.Runtime, runtimeSpec.Memory)
-	success, executionRecordBase, res := httpInvocation(dataString, function, i.announceDoneExe, false)
+	success, record, res := httpInvocation(ctx, i.client, dataString, &Payload{}, function)
 
-	executionRecordBase.RequestedDuration = uint32(runtimeSpec.Runtime * 1e3)
-	record := &mc.ExecutionRecord{ExecutionRecordBase: *executionRecordBase}
+	record.RequestedDuration = uint32(runtimeSpec.Runtime * 1e3)
 
 	if !success {
 		return false, record
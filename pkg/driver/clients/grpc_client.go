@@ -26,6 +26,7 @@ package clients
 
 import (
 	"context"
+	protobuf "github.com/golang/protobuf/proto"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/vhive-serverless/loader/pkg/common"
@@ -34,7 +35,9 @@ import (
 	helloworld "github.com/vhive-serverless/vSwarm/utils/protobuf/helloworld"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"strings"
 	"time"
 
@@ -42,20 +45,25 @@ import (
 )
 
 type invoker interface {
-	Invoke(function *common.Function, runtimeSpec *common.RuntimeSpecification, conn *grpc.ClientConn, record *mc.ExecutionRecord, executionCxt context.Context) bool
+	Invoke(function *common.Function, runtimeSpec *common.RuntimeSpecification, payload *Payload, conn *grpc.ClientConn, record *mc.ExecutionRecord, executionCxt context.Context) bool
 }
 
 type ExecutorRPC struct {
 }
 
-func (i ExecutorRPC) Invoke(function *common.Function, runtimeSpec *common.RuntimeSpecification, conn *grpc.ClientConn, record *mc.ExecutionRecord, executionCxt context.Context) bool {
+func (i ExecutorRPC) Invoke(function *common.Function, runtimeSpec *common.RuntimeSpecification, payload *Payload, conn *grpc.ClientConn, record *mc.ExecutionRecord, executionCxt context.Context) bool {
 	grpcClient := proto.NewExecutorClient(conn)
 
-	response, err := grpcClient.Execute(executionCxt, &proto.FaasRequest{
-		Message:           "nothing",
-		RuntimeInMilliSec: uint32(runtimeSpec.Runtime),
-		MemoryInMebiBytes: uint32(runtimeSpec.Memory),
-	})
+	request := &proto.FaasRequest{
+		Message:             "nothing",
+		RuntimeInMilliSec:   uint32(runtimeSpec.Runtime),
+		MemoryInMebiBytes:   uint32(runtimeSpec.Memory),
+		Payload:             payload.Request,
+		ResponseSizeInBytes: uint32(payload.ResponseSize),
+	}
+	record.RequestBytes = protobuf.Size(request)
+
+	response, err := grpcClient.Execute(executionCxt, request)
 
 	if err != nil {
 		logrus.Debugf("gRPC timeout exceeded for function %s - %s", function.Name, err)
@@ -68,6 +76,10 @@ func (i ExecutorRPC) Invoke(function *common.Function, runtimeSpec *common.Runti
 
 	record.Instance = extractInstanceName(response.GetMessage())
 	record.ActualDuration = response.DurationInMicroSec
+	record.ResponseBytes = protobuf.Size(response)
+	if !payload.isEmpty() {
+		record.PayloadValidation = validatePayload(payload, response.GetPayload(), response.GetPayloadChecksum(), response.GetRequestChecksum())
+	}
 
 	if strings.HasPrefix(response.GetMessage(), "FAILURE - mem_alloc") {
 		record.MemoryAllocationTimeout = true
@@ -84,7 +96,7 @@ func (i ExecutorRPC) Invoke(function *common.Function, runtimeSpec *common.Runti
 type SayHelloRPC struct {
 }
 
-func (i SayHelloRPC) Invoke(function *common.Function, runtimeSpec *common.RuntimeSpecification, conn *grpc.ClientConn, record *mc.ExecutionRecord, executionCxt context.Context) bool {
+func (i SayHelloRPC) Invoke(function *common.Function, runtimeSpec *common.RuntimeSpecification, _ *Payload, conn *grpc.ClientConn, record *mc.ExecutionRecord, executionCxt context.Context) bool {
 	grpcClient := helloworld.NewGreeterClient(conn)
 	response, err := grpcClient.SayHello(executionCxt, &helloworld.HelloRequest{
 		Name: "Invoke Relay",
@@ -111,16 +123,80 @@ func (i SayHelloRPC) Invoke(function *common.Function, runtimeSpec *common.Runti
 type grpcInvoker struct {
 	cfg     *config.LoaderConfiguration
 	invoker invoker
+	// pool is nil if every invocation opens a fresh connection
+	pool     *grpcConnectionPool
+	payloads *payloadGenerator
+	// credentials of the connections, TLS if configured and plain text otherwise
+	credentials credentials.TransportCredentials
 }
 
 func newGRPCInvoker(cfg *config.LoaderConfiguration, invoker invoker) *grpcInvoker {
-	return &grpcInvoker{
-		cfg:     cfg,
-		invoker: invoker,
+	result := &grpcInvoker{
+		cfg:         cfg,
+		invoker:     invoker,
+		payloads:    newPayloadGenerator(cfg),
+		credentials: insecure.NewCredentials(),
+	}
+	if tlsConfig := NewTLSConfig(cfg); tlsConfig != nil {
+		result.credentials = credentials.NewTLS(tlsConfig)
+	}
+
+	if !cfg.GRPCFreshConnectionPerInvocation {
+		result.pool = newGRPCConnectionPool(cfg.GRPCConnectionPoolSize, time.Duration(cfg.GRPCConnectionIdleTimeoutSeconds)*time.Second)
+	}
+
+	return result
+}
+
+// connect returns a ready connection to the function, taken from the pool unless fresh connections are requested, in
+// which case the caller closes it after the invocation
+func (i *grpcInvoker) connect(ctx context.Context, function *common.Function) (*grpc.ClientConn, error) {
+	var dialOptions []grpc.DialOption
+	dialOptions = append(dialOptions, grpc.WithTransportCredentials(i.credentials))
+
+	endpoint := grpcEndpoint{target: function.Endpoint}
+	if strings.Contains(strings.ToLower(i.cfg.Platform), "dirigent") {
+		endpoint.authority = function.Name
+		dialOptions = append(dialOptions, grpc.WithAuthority(function.Name)) // Dirigent specific
+	}
+	if i.cfg.EnableZipkinTracing {
+		dialOptions = append(dialOptions, grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
+	}
+
+	var conn *grpc.ClientConn
+	var err error
+	if i.pool != nil {
+		conn, err = i.pool.get(endpoint, dialOptions)
+	} else {
+		conn, err = grpc.NewClient(function.Endpoint, dialOptions...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the connection is established within the function timeout unless a connection timeout is set
+	timeout := i.cfg.GRPCConnectionTimeoutSeconds
+	if timeout <= 0 {
+		timeout = i.cfg.GRPCFunctionTimeoutSeconds
 	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		defer cancel()
+	}
+
+	if err = waitForReady(ctx, conn); err != nil {
+		if i.pool == nil {
+			gRPCConnectionClose(conn)
+		}
+
+		return nil, err
+	}
+
+	return conn, nil
 }
 
-func (i *grpcInvoker) Invoke(function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *mc.ExecutionRecord) {
+func (i *grpcInvoker) Invoke(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *mc.ExecutionRecord) {
 	logrus.Tracef("(Invoke)\t %s: %d[ms], %d[MiB]", function.Name, runtimeSpec.Runtime, runtimeSpec.Memory)
 
 	record := &mc.ExecutionRecord{
@@ -135,18 +211,9 @@ func (i *grpcInvoker) Invoke(function *common.Function, runtimeSpec *common.Runt
 	start := time.Now()
 	record.StartTime = start.UnixMicro()
 
-	var dialOptions []grpc.DialOption
-	dialOptions = append(dialOptions, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if strings.Contains(strings.ToLower(i.cfg.Platform), "dirigent") {
-		dialOptions = append(dialOptions, grpc.WithAuthority(function.Name)) // Dirigent specific
-	}
-	if i.cfg.EnableZipkinTracing {
-		dialOptions = append(dialOptions, grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
-	}
-
 	grpcStart := time.Now()
 
-	conn, err := grpc.NewClient(function.Endpoint, dialOptions...)
+	conn, err := i.connect(ctx, function)
 	if err != nil {
 		logrus.Debugf("Failed to establish a gRPC connection - %v\n", err)
 
@@ -155,12 +222,18 @@ func (i *grpcInvoker) Invoke(function *common.Function, runtimeSpec *common.Runt
 
 		return false, record
 	}
-	defer gRPCConnectionClose(conn)
+	if i.pool == nil {
+		defer gRPCConnectionClose(conn)
+	}
 
+	// time to get a ready connection, which is close to zero for a pooled connection that is already established
 	record.GRPCConnectionEstablishTime = time.Since(grpcStart).Microseconds()
-	executionCxt, cancelExecution := context.WithTimeout(context.Background(), time.Duration(i.cfg.GRPCFunctionTimeoutSeconds)*time.Second)
+	executionCxt, cancelExecution := context.WithTimeout(ctx, time.Duration(i.cfg.GRPCFunctionTimeoutSeconds)*time.Second)
 	defer cancelExecution()
-	success := i.invoker.Invoke(function, runtimeSpec, conn, record, executionCxt)
+	for key, value := range RequestHeaders(ctx) {
+		executionCxt = metadata.AppendToOutgoingContext(executionCxt, key, value)
+	}
+	success := i.invoker.Invoke(function, runtimeSpec, i.payloads.payload(function), conn, record, executionCxt)
 	record.ResponseTime = time.Since(start).Microseconds()
 	logrus.Tracef("(E2E Latency) %s: %.2f[ms]\n", function.Name, float64(record.ResponseTime)/1e3)
 	return success, record
@@ -186,6 +259,15 @@ func extractSwarmFunction(data string) string {
 	return data[index+4 : verticalBarIndex]
 }
 
+// Close closes the pooled connections
+func (i *grpcInvoker) Close() error {
+	if i.pool != nil {
+		i.pool.close()
+	}
+
+	return nil
+}
+
 func gRPCConnectionClose(conn *grpc.ClientConn) {
 	if conn == nil {
 		return
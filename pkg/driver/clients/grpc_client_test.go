@@ -33,7 +33,9 @@ import (
 	"github.com/vhive-serverless/loader/pkg/workload/standard"
 	helloworld "github.com/vhive-serverless/vSwarm/utils/protobuf/helloworld"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/reflection"
+	"io"
 	"net"
 	"os"
 	"testing"
@@ -99,7 +101,7 @@ func TestGRPCClientWithServerUnreachable(t *testing.T) {
 	cfg.EnableZipkinTracing = true
 
 	invoker := CreateInvoker(cfg, nil, nil)
-	success, record := invoker.Invoke(&testFunction, &testRuntimeSpecs)
+	success, record := invoker.Invoke(context.Background(), &testFunction, &testRuntimeSpecs)
 
 	if record.Instance != "" ||
 		record.RequestedDuration != uint32(testRuntimeSpecs.Runtime*1000) ||
@@ -116,7 +118,7 @@ func TestVSwarmClientUnreachable(t *testing.T) {
 	cfgSwarm := createFakeVSwarmLoaderConfiguration()
 
 	vSwarmInvoker := CreateInvoker(cfgSwarm, nil, nil)
-	success, record := vSwarmInvoker.Invoke(&testFunction, &testRuntimeSpecs)
+	success, record := vSwarmInvoker.Invoke(context.Background(), &testFunction, &testRuntimeSpecs)
 
 	if record.Instance != "" ||
 		record.RequestedDuration != uint32(testRuntimeSpecs.Runtime*1000) ||
@@ -142,7 +144,7 @@ func TestGRPCClientWithServerReachable(t *testing.T) {
 	invoker := CreateInvoker(cfg, nil, nil)
 
 	start := time.Now()
-	success, record := invoker.Invoke(&testFunction, &testRuntimeSpecs)
+	success, record := invoker.Invoke(context.Background(), &testFunction, &testRuntimeSpecs)
 	logrus.Info("Elapsed: ", time.Since(start).Milliseconds(), " ms")
 
 	if !success ||
@@ -168,7 +170,7 @@ func TestVSwarmClientWithServerReachable(t *testing.T) {
 	vSwarmInvoker := CreateInvoker(cfgSwarm, nil, nil)
 
 	start := time.Now()
-	success, record := vSwarmInvoker.Invoke(&testFunction, &testRuntimeSpecs)
+	success, record := vSwarmInvoker.Invoke(context.Background(), &testFunction, &testRuntimeSpecs)
 	logrus.Info("Elapsed: ", time.Since(start).Milliseconds(), " ms")
 	if !success ||
 		record.MemoryAllocationTimeout != false ||
@@ -200,7 +202,7 @@ func TestGRPCClientWithServerBatchWorkload(t *testing.T) {
 	invoker := CreateInvoker(cfg, nil, nil)
 
 	for i := 0; i < 50; i++ {
-		success, record := invoker.Invoke(&testFunction, &testRuntimeSpecs)
+		success, record := invoker.Invoke(context.Background(), &testFunction, &testRuntimeSpecs)
 
 		if !success ||
 			record.MemoryAllocationTimeout != false ||
@@ -214,3 +216,115 @@ func TestGRPCClientWithServerBatchWorkload(t *testing.T) {
 		}
 	}
 }
+
+func TestGRPCConnectionPool(t *testing.T) {
+	pool := newGRPCConnectionPool(2, 0)
+	defer pool.close()
+
+	dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	endpoint := grpcEndpoint{target: "localhost:18083"}
+
+	first, err := pool.get(endpoint, dialOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := pool.get(endpoint, dialOptions)
+	third, _ := pool.get(endpoint, dialOptions)
+	other, _ := pool.get(grpcEndpoint{target: "localhost:18083", authority: "other-function"}, dialOptions)
+
+	if first == second || first != third {
+		t.Error("Connections of an endpoint should be handed out in a round-robin fashion.")
+	}
+	if other == first || other == second {
+		t.Error("Endpoints with a different authority should not share connections.")
+	}
+}
+
+func TestGRPCClientConnectionReuse(t *testing.T) {
+	address, port := "localhost", 18084
+	function := common.Function{Name: "test-function", Endpoint: fmt.Sprintf("%s:%d", address, port)}
+
+	go standard.StartGRPCServer(address, port, standard.TraceFunction, "")
+	time.Sleep(2 * time.Second)
+
+	for _, fresh := range []bool{false, true} {
+		t.Run(fmt.Sprintf("fresh_%t", fresh), func(t *testing.T) {
+			cfg := createFakeLoaderConfiguration()
+			cfg.EnableZipkinTracing = false
+			cfg.GRPCFreshConnectionPerInvocation = fresh
+
+			invoker := newGRPCInvoker(cfg, ExecutorRPC{})
+			if (invoker.pool == nil) != fresh {
+				t.Fatal("Connection pool should only be used if fresh connections are not requested.")
+			}
+
+			for i := 0; i < 5; i++ {
+				success, record := invoker.Invoke(context.Background(), &function, &testRuntimeSpecs)
+				if !success || record.ConnectionTimeout {
+					t.Fatal("Failed gRPC invocation.")
+				}
+				if record.GRPCConnectionEstablishTime == 0 && (fresh || i == 0) {
+					t.Errorf("Invocation %d should have established a connection.", i)
+				}
+			}
+
+			if !fresh {
+				if conns := invoker.pool.endpoints[grpcEndpoint{target: function.Endpoint}].connections; len(conns) != 1 {
+					t.Errorf("Expected a single pooled connection, got %d.", len(conns))
+				}
+
+				invoker.pool.close()
+			}
+		})
+	}
+}
+
+func TestGRPCClientConnectionTimeout(t *testing.T) {
+	// nothing listens on the port
+	function := common.Function{Name: "test-function", Endpoint: "localhost:18085"}
+
+	tests := []struct {
+		name              string
+		connectionTimeout int
+		functionTimeout   int
+	}{
+		{"connection_timeout", 1, 15},
+		{"function_timeout", 0, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := createFakeLoaderConfiguration()
+			cfg.GRPCConnectionTimeoutSeconds = test.connectionTimeout
+			cfg.GRPCFunctionTimeoutSeconds = test.functionTimeout
+
+			start := time.Now()
+			success, record := newGRPCInvoker(cfg, ExecutorRPC{}).Invoke(context.Background(), &function, &testRuntimeSpecs)
+
+			if success || !record.ConnectionTimeout {
+				t.Error("Invocation of an unreachable function should time out while connecting.")
+			}
+			if elapsed := time.Since(start); elapsed < time.Second || elapsed > 3*time.Second {
+				t.Errorf("Connecting should be bounded by the timeout of one second, took %v.", elapsed)
+			}
+		})
+	}
+}
+
+func TestGRPCClientClose(t *testing.T) {
+	cfg := createFakeLoaderConfiguration()
+	cfg.GRPCConnectionTimeoutSeconds = 1
+	invoker := CreateInvoker(cfg, nil, nil)
+
+	// the connection is pooled even though nothing listens on the port
+	invoker.Invoke(context.Background(), &common.Function{Name: "test-function", Endpoint: "localhost:18085"}, &testRuntimeSpecs)
+
+	pool := invoker.(*closingInvoker).platform.(*grpcInvoker).pool
+	if len(pool.endpoints) != 1 {
+		t.Fatalf("Expected one pooled endpoint, got %d.", len(pool.endpoints))
+	}
+
+	if err := invoker.(io.Closer).Close(); err != nil || len(pool.endpoints) != 0 {
+		t.Errorf("Closing the invoker should close the pooled connections - %v.", err)
+	}
+}
@@ -0,0 +1,115 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+const DefaultGRPCConnectionPoolSize = 1
+
+// grpcEndpoint identifies the connections that can be shared, since Dirigent routes on the authority rather than the
+// address of a function
+type grpcEndpoint struct {
+	target    string
+	authority string
+}
+
+type pooledConnections struct {
+	connections []*grpc.ClientConn
+	next        int
+}
+
+// grpcConnectionPool keeps up to size connections per endpoint and hands them out in a round-robin fashion. gRPC
+// multiplexes concurrent invocations over a connection, so a connection is never checked out exclusively. Connections
+// that are unused for the idle timeout release their transport and reconnect on the next invocation.
+type grpcConnectionPool struct {
+	size        int
+	idleTimeout time.Duration
+
+	mutex     sync.Mutex
+	endpoints map[grpcEndpoint]*pooledConnections
+}
+
+func newGRPCConnectionPool(size int, idleTimeout time.Duration) *grpcConnectionPool {
+	if size <= 0 {
+		size = DefaultGRPCConnectionPoolSize
+	}
+
+	return &grpcConnectionPool{
+		size:        size,
+		idleTimeout: idleTimeout,
+		endpoints:   make(map[grpcEndpoint]*pooledConnections),
+	}
+}
+
+// get returns a connection to the endpoint, creating one if the pool of the endpoint is not full yet. The connection
+// is not necessarily ready.
+func (p *grpcConnectionPool) get(endpoint grpcEndpoint, dialOptions []grpc.DialOption) (*grpc.ClientConn, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	pool, ok := p.endpoints[endpoint]
+	if !ok {
+		pool = &pooledConnections{}
+		p.endpoints[endpoint] = pool
+	}
+
+	if len(pool.connections) < p.size {
+		if p.idleTimeout > 0 {
+			dialOptions = append(dialOptions, grpc.WithIdleTimeout(p.idleTimeout))
+		}
+
+		conn, err := grpc.NewClient(endpoint.target, dialOptions...)
+		if err != nil {
+			return nil, err
+		}
+
+		pool.connections = append(pool.connections, conn)
+		return conn, nil
+	}
+
+	conn := pool.connections[pool.next]
+	pool.next = (pool.next + 1) % len(pool.connections)
+
+	return conn, nil
+}
+
+// close closes all the connections of the pool
+func (p *grpcConnectionPool) close() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for endpoint, pool := range p.endpoints {
+		for _, conn := range pool.connections {
+			gRPCConnectionClose(conn)
+		}
+
+		delete(p.endpoints, endpoint)
+	}
+}
+
+// waitForReady establishes the connection if it is not ready yet. A gRPC client connects lazily, so waiting for the
+// connection is what makes its establishment time measurable separately from the invocation.
+func waitForReady(ctx context.Context, conn *grpc.ClientConn) error {
+	for {
+		state := conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if state == connectivity.Shutdown {
+			return errors.New("gRPC connection has been closed")
+		}
+		if state == connectivity.Idle {
+			conn.Connect()
+		}
+
+		if !conn.WaitForStateChange(ctx, state) {
+			return ctx.Err()
+		}
+	}
+}
@@ -2,6 +2,7 @@ package clients
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	log "github.com/sirupsen/logrus"
 	"github.com/vhive-serverless/loader/pkg/common"
@@ -9,6 +10,7 @@ import (
 	mc "github.com/vhive-serverless/loader/pkg/metric"
 	"io"
 	"net/http"
+	"net/http/httptrace"
 	"strconv"
 	"strings"
 	"time"
@@ -19,21 +21,32 @@ type FunctionResponse struct {
 	Function      string `json:"Function"`
 	MachineName   string `json:"MachineName"`
 	ExecutionTime int64  `json:"ExecutionTime"`
+
+	// Payload of the reply together with the checksums of the reply and of the request, if a payload has been sent
+	Payload         []byte `json:"Payload,omitempty"`
+	PayloadChecksum uint32 `json:"PayloadChecksum,omitempty"`
+	RequestChecksum uint32 `json:"RequestChecksum,omitempty"`
 }
 
 type httpInvoker struct {
-	client *http.Client
-	cfg    *config.LoaderConfiguration
+	client   *http.Client
+	cfg      *config.LoaderConfiguration
+	payloads *payloadGenerator
+	scheme   string
 }
 
 func newHTTPInvoker(cfg *config.LoaderConfiguration) *httpInvoker {
+	tlsConfig := NewTLSConfig(cfg)
+
 	return &httpInvoker{
-		client: CreateHTTPClient(cfg.GRPCFunctionTimeoutSeconds, cfg.InvokeProtocol),
-		cfg:    cfg,
+		client:   CreateHTTPClient(cfg.GRPCFunctionTimeoutSeconds, cfg.InvokeProtocol, tlsConfig),
+		cfg:      cfg,
+		payloads: newPayloadGenerator(cfg),
+		scheme:   urlScheme(tlsConfig),
 	}
 }
 
-func (i *httpInvoker) Invoke(function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *mc.ExecutionRecord) {
+func (i *httpInvoker) Invoke(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *mc.ExecutionRecord) {
 	isDandelion := strings.Contains(strings.ToLower(i.cfg.Platform), "dandelion")
 	isKnative := strings.Contains(strings.ToLower(i.cfg.Platform), "knative")
 
@@ -59,7 +72,14 @@ func (i *httpInvoker) Invoke(function *common.Function, runtimeSpec *common.Runt
 		requestBody = body
 	}
 
-	req, err := http.NewRequest("POST", "http://"+function.Endpoint, requestBody)
+	payload := i.payloads.payload(function)
+	if !isDandelion {
+		requestBody = bytes.NewBuffer(payload.Request)
+	}
+	record.RequestBytes = requestBody.Len()
+
+	timings := &httpTimings{}
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, timings.clientTrace()), "POST", i.scheme+function.Endpoint, requestBody)
 	if err != nil {
 		log.Errorf("Failed to create a HTTP request - %v\n", err)
 
@@ -80,6 +100,12 @@ func (i *httpInvoker) Invoke(function *common.Function, runtimeSpec *common.Runt
 	req.Header.Set("requested_memory", strconv.Itoa(runtimeSpec.Memory))
 	req.Header.Set("multiplier", strconv.Itoa(function.DirigentMetadata.IterationMultiplier))
 	req.Header.Set("io_percentage", strconv.Itoa(function.DirigentMetadata.IOPercentage))
+	if payload.ResponseSize > 0 {
+		req.Header.Set("response_size", strconv.Itoa(payload.ResponseSize))
+	}
+	for key, value := range RequestHeaders(ctx) {
+		req.Header.Set(key, value)
+	}
 
 	if isDandelion {
 		req.URL.Path = "/hot/matmul"
@@ -91,14 +117,17 @@ func (i *httpInvoker) Invoke(function *common.Function, runtimeSpec *common.Runt
 
 		record.ResponseTime = time.Since(start).Microseconds()
 		record.ConnectionTimeout = true
+		timings.fill(record)
 
 		return false, record
 	}
 
-	record.GRPCConnectionEstablishTime = time.Since(start).Microseconds()
-
 	defer HandleBodyClosing(resp)
+	headersReceived := time.Now()
 	body, err := io.ReadAll(resp.Body)
+	record.BodyReadTime = time.Since(headersReceived).Microseconds()
+	record.ResponseBytes = len(body)
+	timings.fill(record)
 
 	if err != nil || resp.StatusCode != http.StatusOK || len(body) == 0 {
 		if err != nil {
@@ -127,6 +156,10 @@ func (i *httpInvoker) Invoke(function *common.Function, runtimeSpec *common.Runt
 		if err != nil {
 			log.Warnf("Failed to deserialize Dirigent response - %v - %v", string(body), err)
 		}
+
+		if !payload.isEmpty() {
+			record.PayloadValidation = validateResponsePayload(payload, body)
+		}
 	}
 
 	record.ResponseTime = time.Since(start).Microseconds()
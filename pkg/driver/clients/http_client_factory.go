@@ -7,19 +7,21 @@ import (
 	"golang.org/x/net/http2"
 	"net"
 	"net/http"
+	"net/http/httptrace"
 	"time"
 )
 
-func CreateHTTPClient(timeout int, invokeProtocol string) *http.Client {
+// CreateHTTPClient creates the client of the given protocol, which sends plain text requests if tlsConfig is nil
+func CreateHTTPClient(timeout int, invokeProtocol string, tlsConfig *tls.Config) *http.Client {
 	client := &http.Client{
 		Timeout: time.Duration(timeout) * time.Second,
 	}
 
 	switch invokeProtocol {
 	case "http1":
-		client.Transport = getHttp1Transport(timeout)
+		client.Transport = getHttp1Transport(timeout, tlsConfig)
 	case "http2":
-		client.Transport = getHttp2Transport()
+		client.Transport = getHttp2Transport(tlsConfig)
 	case "grpc":
 	default:
 		logrus.Errorf("Invalid invoke protocol in the configuration file.")
@@ -28,11 +30,12 @@ func CreateHTTPClient(timeout int, invokeProtocol string) *http.Client {
 	return client
 }
 
-func getHttp1Transport(timeout int) *http.Transport {
+func getHttp1Transport(timeout int, tlsConfig *tls.Config) *http.Transport {
 	return &http.Transport{
 		DialContext: (&net.Dialer{
 			Timeout: time.Duration(timeout) * time.Second,
 		}).DialContext,
+		TLSClientConfig:     tlsConfig,
 		IdleConnTimeout:     5 * time.Second,
 		MaxIdleConns:        100,
 		MaxIdleConnsPerHost: 10,
@@ -40,11 +43,46 @@ func getHttp1Transport(timeout int) *http.Transport {
 	}
 }
 
-func getHttp2Transport() *http2.Transport {
+func getHttp2Transport(tlsConfig *tls.Config) *http2.Transport {
+	if tlsConfig != nil {
+		return &http2.Transport{
+			TLSClientConfig: tlsConfig,
+			DialTLSContext:  dialHttp2TLS,
+		}
+	}
+
 	return &http2.Transport{
 		AllowHTTP: true,
 		DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
-			return net.Dial(network, addr)
+			// dialing with the context of the request makes the dial cancellable and visible to its httptrace
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
 		},
 	}
 }
+
+// dialHttp2TLS negotiates HTTP/2 over TLS, reporting the handshake to the httptrace of the request as the transport
+// does not
+func dialHttp2TLS(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+	conn, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	trace := httptrace.ContextClientTrace(ctx)
+	if trace != nil && trace.TLSHandshakeStart != nil {
+		trace.TLSHandshakeStart()
+	}
+
+	tlsConn := tls.Client(conn, cfg)
+	err = tlsConn.HandshakeContext(ctx)
+
+	if trace != nil && trace.TLSHandshakeDone != nil {
+		trace.TLSHandshakeDone(tlsConn.ConnectionState(), err)
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return tlsConn, nil
+}
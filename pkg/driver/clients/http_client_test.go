@@ -0,0 +1,96 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func startHTTPTestServer(t *testing.T, delay time.Duration) *httptest.Server {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		_, _ = w.Write([]byte(`{"Status": "OK", "Function": "test-function", "MachineName": "test", "ExecutionTime": 1000}`))
+	})
+
+	server := httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestHTTPClientLatencyBreakdown(t *testing.T) {
+	server := startHTTPTestServer(t, 20*time.Millisecond)
+
+	function := &common.Function{
+		Name:             "test-function",
+		Endpoint:         strings.TrimPrefix(server.URL, "http://"),
+		DirigentMetadata: &common.DirigentMetadata{},
+	}
+
+	for _, protocol := range []string{"http1", "http2"} {
+		t.Run(protocol, func(t *testing.T) {
+			invoker := newHTTPInvoker(&config.LoaderConfiguration{
+				Platform:                   "Dirigent",
+				InvokeProtocol:             protocol,
+				GRPCFunctionTimeoutSeconds: 5,
+			})
+
+			for i := 0; i < 2; i++ {
+				success, record := invoker.Invoke(context.Background(), function, &testRuntimeSpecs)
+				if !success {
+					t.Fatal("HTTP invocation failed.")
+				}
+
+				if record.ConnectionReused != (i > 0) {
+					t.Errorf("Invocation %d - connection reused: %t.", i, record.ConnectionReused)
+				}
+				if i == 0 && record.TCPConnectTime == 0 {
+					t.Error("First invocation should have connected.")
+				}
+				if i > 0 && (record.TCPConnectTime != 0 || record.DNSLookupTime != 0) {
+					t.Error("Reused connection should not have connected.")
+				}
+				if record.TLSHandshakeTime != 0 {
+					t.Error("No TLS handshake should happen in plain text.")
+				}
+				if record.TimeToFirstByte < (20 * time.Millisecond).Microseconds() {
+					t.Errorf("Time to first byte should include the processing of the request, got %d us.", record.TimeToFirstByte)
+				}
+				if record.GRPCConnectionEstablishTime+record.RequestWriteTime+record.TimeToFirstByte+record.BodyReadTime > record.ResponseTime {
+					t.Error("Breakdown should not exceed the response time.")
+				}
+				if record.RequestBytes != 0 || record.ResponseBytes == 0 || record.PayloadValidation != "" {
+					t.Errorf("Unexpected body sizes - request: %d, response: %d.", record.RequestBytes, record.ResponseBytes)
+				}
+				if record.Instance != "test-function" || record.ActualDuration != 1000 {
+					t.Errorf("Unexpected response - %+v.", record)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPClientServerUnreachable(t *testing.T) {
+	server := startHTTPTestServer(t, 0)
+	endpoint := strings.TrimPrefix(server.URL, "http://")
+	server.Close()
+
+	invoker := newHTTPInvoker(&config.LoaderConfiguration{Platform: "Dirigent", InvokeProtocol: "http1", GRPCFunctionTimeoutSeconds: 5})
+	success, record := invoker.Invoke(context.Background(), &common.Function{
+		Name:             "test-function",
+		Endpoint:         endpoint,
+		DirigentMetadata: &common.DirigentMetadata{},
+	}, &testRuntimeSpecs)
+
+	if success || !record.ConnectionTimeout || record.ConnectionReused || record.TimeToFirstByte != 0 {
+		t.Errorf("Unexpected record of an unreachable server - %+v.", record)
+	}
+}
@@ -0,0 +1,87 @@
+package clients
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+// httpTimings collects the client-side events of an HTTP invocation. The transport may fire events from its own
+// goroutines, e.g., a dial that completes after the request has been cancelled, so the events are guarded by a mutex.
+type httpTimings struct {
+	mutex sync.Mutex
+
+	getConn, gotConn          time.Time
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	wroteRequest, firstByte   time.Time
+	connectionReused          bool
+}
+
+func (t *httpTimings) mark(event *time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	// only the first occurrence counts, as a request may race several dials
+	if event.IsZero() {
+		*event = time.Now()
+	}
+}
+
+func (t *httpTimings) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) { t.mark(&t.getConn) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mark(&t.gotConn)
+
+			t.mutex.Lock()
+			t.connectionReused = info.Reused
+			t.mutex.Unlock()
+		},
+		DNSStart: func(httptrace.DNSStartInfo) { t.mark(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.mark(&t.dnsDone) },
+		ConnectStart: func(string, string) {
+			t.mark(&t.connectStart)
+		},
+		ConnectDone: func(_ string, _ string, err error) {
+			if err == nil {
+				t.mark(&t.connectDone)
+			}
+		},
+		TLSHandshakeStart: func() { t.mark(&t.tlsStart) },
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err == nil {
+				t.mark(&t.tlsDone)
+			}
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.mark(&t.wroteRequest) },
+		GotFirstResponseByte: func() { t.mark(&t.firstByte) },
+	}
+}
+
+// span returns the time between two events in microseconds, or 0 if either of them has not occurred
+func span(from time.Time, to time.Time) int64 {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return 0
+	}
+
+	return to.Sub(from).Microseconds()
+}
+
+// fill writes the breakdown of the invocation into the record
+func (t *httpTimings) fill(record *mc.ExecutionRecord) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	record.GRPCConnectionEstablishTime = span(t.getConn, t.gotConn)
+	record.DNSLookupTime = span(t.dnsStart, t.dnsDone)
+	record.TCPConnectTime = span(t.connectStart, t.connectDone)
+	record.TLSHandshakeTime = span(t.tlsStart, t.tlsDone)
+	record.RequestWriteTime = span(t.gotConn, t.wroteRequest)
+	record.TimeToFirstByte = span(t.wroteRequest, t.firstByte)
+	record.ConnectionReused = t.connectionReused
+}
@@ -1,6 +1,8 @@
 package clients
 
 import (
+	"context"
+	"io"
 	"sync"
 
 	"github.com/sirupsen/logrus"
@@ -10,13 +12,57 @@ import (
 )
 
 type Invoker interface {
-	Invoke(*common.Function, *common.RuntimeSpecification) (bool, *metric.ExecutionRecord)
+	// Invoke issues a single invocation. In-flight requests are aborted once the context is cancelled.
+	Invoke(context.Context, *common.Function, *common.RuntimeSpecification) (bool, *metric.ExecutionRecord)
 }
 
+// CreateInvoker creates the invoker of the platform wrapped with the middlewares enabled in the configuration. The
+// invoker is an io.Closer, which releases the connections of the platform invoker once the experiment has ended.
 func CreateInvoker(cfg *config.LoaderConfiguration, announceDoneExe *sync.WaitGroup, readOpenWhiskMetadata *sync.Mutex) Invoker {
+	platform := createPlatformInvoker(cfg, announceDoneExe, readOpenWhiskMetadata)
+
+	return &closingInvoker{
+		Invoker:  Chain(platform, NewMiddlewares(cfg)...),
+		platform: platform,
+	}
+}
+
+type closingInvoker struct {
+	Invoker
+	platform Invoker
+}
+
+func (i *closingInvoker) Close() error {
+	if closer, ok := i.platform.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+type announceDoneKey struct{}
+
+// WithAnnounceDone attaches to the context the announcement that an invocation has been issued, which marks it done in
+// the wait group once, no matter how many attempts the middlewares make. The returned function announces it as well
+// and is deferred by the caller, so that invocations never reaching the platform invoker are announced too.
+func WithAnnounceDone(ctx context.Context, announceDoneExe *sync.WaitGroup) (context.Context, func()) {
+	once := &sync.Once{}
+	announce := func() { once.Do(announceDoneExe.Done) }
+
+	return context.WithValue(ctx, announceDoneKey{}, announce), announce
+}
+
+// announceDone announces that the invocation has been issued, if the context has been created by WithAnnounceDone
+func announceDone(ctx context.Context) {
+	if announce, ok := ctx.Value(announceDoneKey{}).(func()); ok {
+		announce()
+	}
+}
+
+func createPlatformInvoker(cfg *config.LoaderConfiguration, announceDoneExe *sync.WaitGroup, readOpenWhiskMetadata *sync.Mutex) Invoker {
 	switch cfg.Platform {
 	case "AWSLambda":
-		return newAWSLambdaInvoker(announceDoneExe)
+		return newAWSLambdaInvoker(cfg)
 	case "Dirigent":
 		if cfg.InvokeProtocol == "grpc" {
 			return newGRPCInvoker(cfg, ExecutorRPC{})
@@ -36,7 +82,7 @@ func CreateInvoker(cfg *config.LoaderConfiguration, announceDoneExe *sync.WaitGr
 			return newHTTPInvoker(cfg)
 		}
 	case "OpenWhisk":
-		return newOpenWhiskInvoker(announceDoneExe, readOpenWhiskMetadata)
+		return newOpenWhiskInvoker(cfg, announceDoneExe, readOpenWhiskMetadata)
 	default:
 		logrus.Fatal("Unsupported platform.")
 	}
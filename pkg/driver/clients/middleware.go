@@ -0,0 +1,312 @@
+package clients
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	"github.com/vhive-serverless/loader/pkg/metric"
+)
+
+// Middleware wraps an invoker with cross-cutting behavior. Middlewares record their effect in the ExecutionRecord of
+// the invocation.
+type Middleware func(Invoker) Invoker
+
+// InvokerFunc adapts a function to the Invoker interface
+type InvokerFunc func(context.Context, *common.Function, *common.RuntimeSpecification) (bool, *metric.ExecutionRecord)
+
+func (f InvokerFunc) Invoke(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+	return f(ctx, function, runtimeSpec)
+}
+
+// Chain wraps the invoker with the middlewares, the first middleware being the outermost one
+func Chain(invoker Invoker, middlewares ...Middleware) Invoker {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		invoker = middlewares[i](invoker)
+	}
+
+	return invoker
+}
+
+// NewMiddlewares creates the middlewares enabled in the configuration. Retries wrap everything else, so that every
+// attempt is rate limited, capped and exposed to the injected faults.
+func NewMiddlewares(cfg *config.LoaderConfiguration) []Middleware {
+	var result []Middleware
+
+	retries := cfg.InvocationRetries
+	if retries == 0 && cfg.DAGMode {
+		// nodes of a workflow have always been retried once
+		retries = 1
+	}
+	if retries > 0 {
+		result = append(result, WithRetry(RetryPolicy{
+			MaxRetries:     retries,
+			InitialBackoff: time.Duration(cfg.InvocationRetryBackoffMs) * time.Millisecond,
+			MaxBackoff:     time.Duration(cfg.InvocationRetryMaxBackoffMs) * time.Millisecond,
+			Jitter:         cfg.InvocationRetryJitter,
+		}))
+	}
+	if cfg.ClientRateLimitRPS > 0 {
+		result = append(result, WithRateLimit(cfg.ClientRateLimitRPS, cfg.ClientRateLimitBurst))
+	}
+	if cfg.MaxInFlightPerFunction > 0 {
+		result = append(result, WithInFlightLimit(cfg.MaxInFlightPerFunction))
+	}
+	if cfg.FaultDropPercentage > 0 || cfg.FaultDelayPercentage > 0 {
+		result = append(result, WithFaultInjection(FaultInjection{
+			DropPercentage:  cfg.FaultDropPercentage,
+			DelayPercentage: cfg.FaultDelayPercentage,
+			Delay:           time.Duration(cfg.FaultDelayMs) * time.Millisecond,
+		}))
+	}
+	if len(cfg.InvocationHeaders) > 0 {
+		result = append(result, WithHeaders(cfg.InvocationHeaders))
+	}
+
+	return result
+}
+
+// failedRecord is the record of an invocation that has not been issued
+func failedRecord(start time.Time, runtimeSpec *common.RuntimeSpecification) *metric.ExecutionRecord {
+	return &metric.ExecutionRecord{
+		ExecutionRecordBase: metric.ExecutionRecordBase{
+			RequestedDuration: uint32(runtimeSpec.Runtime * 1e3),
+			StartTime:         start.UnixMicro(),
+			ResponseTime:      time.Since(start).Microseconds(),
+		},
+	}
+}
+
+// sleep waits for the duration unless the context is cancelled first
+func sleep(ctx context.Context, duration time.Duration) bool {
+	if duration <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+/////////////////////////////////////////
+// RETRIES
+/////////////////////////////////////////
+
+type RetryPolicy struct {
+	MaxRetries int
+	// InitialBackoff is doubled after every attempt up to MaxBackoff, if set
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Jitter is the fraction of the backoff that is randomized, i.e., the backoff is drawn from
+	// [backoff * (1 - Jitter), backoff]
+	Jitter float64
+}
+
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	backoff := float64(p.InitialBackoff) * math.Pow(2, float64(retry))
+	if p.MaxBackoff > 0 {
+		backoff = math.Min(backoff, float64(p.MaxBackoff))
+	}
+
+	return time.Duration(backoff * (1 - p.Jitter*rand.Float64()))
+}
+
+// WithRetry retries failed invocations with an exponential backoff. The record of the last attempt is returned with the
+// number of retries.
+func WithRetry(policy RetryPolicy) Middleware {
+	return func(next Invoker) Invoker {
+		return InvokerFunc(func(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+			success, record := next.Invoke(ctx, function, runtimeSpec)
+
+			for retry := 0; !success && retry < policy.MaxRetries && ctx.Err() == nil; retry++ {
+				if !sleep(ctx, policy.backoff(retry)) {
+					break
+				}
+
+				logrus.Debugf("Invocation of function %s failed. Retrying invocation (%d/%d).", function.Name, retry+1, policy.MaxRetries)
+
+				success, record = next.Invoke(ctx, function, runtimeSpec)
+				record.Retries = retry + 1
+			}
+
+			return success, record
+		})
+	}
+}
+
+/////////////////////////////////////////
+// RATE LIMITING
+/////////////////////////////////////////
+
+// tokenBucket refills at rate tokens per second up to burst tokens. Tokens are reserved ahead of time, so that the
+// invocations are released in the order they have arrived.
+type tokenBucket struct {
+	mutex    sync.Mutex
+	rate     float64
+	burst    float64
+	tokens   float64
+	lastFill time.Time
+}
+
+// reserve takes a token and returns how long the caller has to wait for it
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.tokens = math.Min(b.tokens+now.Sub(b.lastFill).Seconds()*b.rate, b.burst)
+	b.lastFill = now
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// WithRateLimit caps the rate of invocations issued by the loader across all functions with a token bucket
+func WithRateLimit(rps float64, burst int) Middleware {
+	burst = max(burst, 1)
+	bucket := &tokenBucket{rate: rps, burst: float64(burst), tokens: float64(burst), lastFill: time.Now()}
+
+	return func(next Invoker) Invoker {
+		return InvokerFunc(func(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+			start := time.Now()
+			if !sleep(ctx, bucket.reserve(start)) {
+				record := failedRecord(start, runtimeSpec)
+				record.RateLimitDelay = record.ResponseTime
+
+				return false, record
+			}
+
+			delay := time.Since(start).Microseconds()
+			success, record := next.Invoke(ctx, function, runtimeSpec)
+			record.RateLimitDelay = delay
+
+			return success, record
+		})
+	}
+}
+
+/////////////////////////////////////////
+// IN-FLIGHT LIMIT
+/////////////////////////////////////////
+
+// WithInFlightLimit caps the number of concurrent invocations of each function. Invocations above the cap wait for a
+// slot.
+func WithInFlightLimit(limit int) Middleware {
+	var slots sync.Map
+
+	return func(next Invoker) Invoker {
+		return InvokerFunc(func(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+			semaphore, _ := slots.LoadOrStore(function.Name, make(chan struct{}, limit))
+
+			start := time.Now()
+			select {
+			case semaphore.(chan struct{}) <- struct{}{}:
+			case <-ctx.Done():
+				record := failedRecord(start, runtimeSpec)
+				record.InFlightLimitDelay = record.ResponseTime
+
+				return false, record
+			}
+			defer func() { <-semaphore.(chan struct{}) }()
+
+			delay := time.Since(start).Microseconds()
+			success, record := next.Invoke(ctx, function, runtimeSpec)
+			record.InFlightLimitDelay = delay
+
+			return success, record
+		})
+	}
+}
+
+/////////////////////////////////////////
+// FAULT INJECTION
+/////////////////////////////////////////
+
+const (
+	FaultDrop  = "drop"
+	FaultDelay = "delay"
+)
+
+type FaultInjection struct {
+	// DropPercentage of the invocations fail without being issued
+	DropPercentage float64
+	// DelayPercentage of the invocations are issued after Delay
+	DelayPercentage float64
+	Delay           time.Duration
+}
+
+// WithFaultInjection drops or delays a percentage of the invocations on the client side
+func WithFaultInjection(faults FaultInjection) Middleware {
+	return func(next Invoker) Invoker {
+		return InvokerFunc(func(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+			draw := rand.Float64() * 100
+
+			if draw < faults.DropPercentage {
+				record := failedRecord(time.Now(), runtimeSpec)
+				record.InjectedFault = FaultDrop
+
+				return false, record
+			}
+
+			if draw < faults.DropPercentage+faults.DelayPercentage {
+				start := time.Now()
+				if !sleep(ctx, faults.Delay) {
+					record := failedRecord(start, runtimeSpec)
+					record.InjectedFault = FaultDelay
+
+					return false, record
+				}
+
+				success, record := next.Invoke(ctx, function, runtimeSpec)
+				record.InjectedFault = FaultDelay
+
+				return success, record
+			}
+
+			return next.Invoke(ctx, function, runtimeSpec)
+		})
+	}
+}
+
+/////////////////////////////////////////
+// HEADERS
+/////////////////////////////////////////
+
+type headersKey struct{}
+
+// WithHeaders adds headers to the HTTP requests and metadata to the gRPC requests of the invocations
+func WithHeaders(headers map[string]string) Middleware {
+	return func(next Invoker) Invoker {
+		return InvokerFunc(func(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+			merged := make(map[string]string, len(headers))
+			for key, value := range RequestHeaders(ctx) {
+				merged[key] = value
+			}
+			for key, value := range headers {
+				merged[key] = value
+			}
+
+			return next.Invoke(context.WithValue(ctx, headersKey{}, merged), function, runtimeSpec)
+		})
+	}
+}
+
+// RequestHeaders returns the headers the middlewares have attached to the invocation
+func RequestHeaders(ctx context.Context) map[string]string {
+	headers, _ := ctx.Value(headersKey{}).(map[string]string)
+	return headers
+}
@@ -0,0 +1,234 @@
+package clients
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	"github.com/vhive-serverless/loader/pkg/metric"
+)
+
+// fakeInvoker fails the first failures invocations and takes duration per invocation
+func fakeInvoker(failures int32, duration time.Duration) (Invoker, *atomic.Int32) {
+	calls := &atomic.Int32{}
+
+	return InvokerFunc(func(ctx context.Context, _ *common.Function, _ *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+		call := calls.Add(1)
+		time.Sleep(duration)
+
+		return call > failures, &metric.ExecutionRecord{}
+	}), calls
+}
+
+func TestChain(t *testing.T) {
+	var order []string
+	tag := func(name string) Middleware {
+		return func(next Invoker) Invoker {
+			return InvokerFunc(func(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+				order = append(order, name)
+				return next.Invoke(ctx, function, runtimeSpec)
+			})
+		}
+	}
+
+	invoker, _ := fakeInvoker(0, 0)
+	Chain(invoker, tag("outer"), tag("inner")).Invoke(context.Background(), &testFunction, &testRuntimeSpecs)
+
+	if len(order) != 2 || order[0] != "outer" || order[1] != "inner" {
+		t.Errorf("Unexpected order of the middlewares %v.", order)
+	}
+}
+
+func TestRetryMiddleware(t *testing.T) {
+	invoker, calls := fakeInvoker(2, 0)
+	retrying := Chain(invoker, WithRetry(RetryPolicy{MaxRetries: 3, InitialBackoff: 10 * time.Millisecond}))
+
+	start := time.Now()
+	success, record := retrying.Invoke(context.Background(), &testFunction, &testRuntimeSpecs)
+
+	if !success || record.Retries != 2 || calls.Load() != 3 {
+		t.Errorf("Expected success after 2 retries, got %t after %d retries and %d calls.", success, record.Retries, calls.Load())
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("Backoff should double after every attempt, took %v.", elapsed)
+	}
+
+	invoker, calls = fakeInvoker(10, 0)
+	success, record = Chain(invoker, WithRetry(RetryPolicy{MaxRetries: 2, Jitter: 1})).Invoke(context.Background(), &testFunction, &testRuntimeSpecs)
+	if success || record.Retries != 2 || calls.Load() != 3 {
+		t.Errorf("Expected failure after 2 retries, got %t after %d retries.", success, record.Retries)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	invoker, calls = fakeInvoker(10, 0)
+	Chain(invoker, WithRetry(RetryPolicy{MaxRetries: 5})).Invoke(ctx, &testFunction, &testRuntimeSpecs)
+	if calls.Load() != 1 {
+		t.Errorf("Cancelled invocations should not be retried, got %d calls.", calls.Load())
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	invoker, _ := fakeInvoker(0, 0)
+	limited := Chain(invoker, WithRateLimit(100, 1))
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		_, record := limited.Invoke(context.Background(), &testFunction, &testRuntimeSpecs)
+		if i == 0 && record.RateLimitDelay > 1000 {
+			t.Errorf("First invocation should not wait, waited %d us.", record.RateLimitDelay)
+		}
+		if i > 0 && record.RateLimitDelay == 0 {
+			t.Errorf("Invocation %d should have been delayed.", i)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("5 invocations at 100 RPS should take at least 40 ms, took %v.", elapsed)
+	}
+}
+
+func TestInFlightLimitMiddleware(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	invoker := InvokerFunc(func(ctx context.Context, _ *common.Function, _ *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+		current := inFlight.Add(1)
+		for {
+			observed := maxInFlight.Load()
+			if current <= observed || maxInFlight.CompareAndSwap(observed, current) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		inFlight.Add(-1)
+
+		return true, &metric.ExecutionRecord{}
+	})
+	limited := Chain(invoker, WithInFlightLimit(2))
+
+	var wg sync.WaitGroup
+	var delayed atomic.Int32
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, record := limited.Invoke(context.Background(), &testFunction, &testRuntimeSpecs)
+			if record.InFlightLimitDelay > 0 {
+				delayed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight.Load() != 2 {
+		t.Errorf("Expected at most 2 invocations in flight, got %d.", maxInFlight.Load())
+	}
+	if delayed.Load() < 4 {
+		t.Errorf("Expected at least 4 delayed invocations, got %d.", delayed.Load())
+	}
+}
+
+func TestFaultInjectionMiddleware(t *testing.T) {
+	invoker, calls := fakeInvoker(0, 0)
+
+	success, record := Chain(invoker, WithFaultInjection(FaultInjection{DropPercentage: 100})).Invoke(context.Background(), &testFunction, &testRuntimeSpecs)
+	if success || record.InjectedFault != FaultDrop || calls.Load() != 0 {
+		t.Error("Dropped invocation should fail without being issued.")
+	}
+
+	start := time.Now()
+	success, record = Chain(invoker, WithFaultInjection(FaultInjection{DelayPercentage: 100, Delay: 20 * time.Millisecond})).Invoke(context.Background(), &testFunction, &testRuntimeSpecs)
+	if !success || record.InjectedFault != FaultDelay || calls.Load() != 1 || time.Since(start) < 20*time.Millisecond {
+		t.Error("Delayed invocation should be issued after the delay.")
+	}
+
+	success, record = Chain(invoker, WithFaultInjection(FaultInjection{})).Invoke(context.Background(), &testFunction, &testRuntimeSpecs)
+	if !success || record.InjectedFault != "" {
+		t.Error("No fault should be injected.")
+	}
+}
+
+func TestHeadersMiddleware(t *testing.T) {
+	var headers map[string]string
+	invoker := InvokerFunc(func(ctx context.Context, _ *common.Function, _ *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+		headers = RequestHeaders(ctx)
+		return true, &metric.ExecutionRecord{}
+	})
+
+	Chain(invoker, WithHeaders(map[string]string{"a": "1", "b": "2"}), WithHeaders(map[string]string{"b": "3"})).
+		Invoke(context.Background(), &testFunction, &testRuntimeSpecs)
+
+	if len(headers) != 2 || headers["a"] != "1" || headers["b"] != "3" {
+		t.Errorf("Unexpected headers %v.", headers)
+	}
+}
+
+func TestNewMiddlewares(t *testing.T) {
+	if middlewares := NewMiddlewares(&config.LoaderConfiguration{}); len(middlewares) != 0 {
+		t.Errorf("No middleware should be enabled by default, got %d.", len(middlewares))
+	}
+
+	// nodes of a workflow are retried once by default
+	invoker, calls := fakeInvoker(1, 0)
+	success, record := Chain(invoker, NewMiddlewares(&config.LoaderConfiguration{DAGMode: true})...).Invoke(context.Background(), &testFunction, &testRuntimeSpecs)
+	if !success || record.Retries != 1 || calls.Load() != 2 {
+		t.Error("Failed invocation should be retried once in DAG mode.")
+	}
+
+	middlewares := NewMiddlewares(&config.LoaderConfiguration{
+		InvocationRetries:      1,
+		ClientRateLimitRPS:     10,
+		MaxInFlightPerFunction: 1,
+		FaultDropPercentage:    1,
+		InvocationHeaders:      map[string]string{"a": "1"},
+	})
+	if len(middlewares) != 5 {
+		t.Errorf("Expected 5 middlewares, got %d.", len(middlewares))
+	}
+}
+
+func TestAnnounceDone(t *testing.T) {
+	announcingInvoker := func(calls *atomic.Int32) Invoker {
+		return InvokerFunc(func(ctx context.Context, _ *common.Function, _ *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+			calls.Add(1)
+			announceDone(ctx)
+
+			return false, &metric.ExecutionRecord{}
+		})
+	}
+
+	tests := []struct {
+		name        string
+		middlewares []Middleware
+		calls       int32
+	}{
+		{"retries", []Middleware{WithRetry(RetryPolicy{MaxRetries: 3})}, 4},
+		{"dropped", []Middleware{WithFaultInjection(FaultInjection{DropPercentage: 100})}, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			announceDoneExe := &sync.WaitGroup{}
+			announceDoneExe.Add(2)
+
+			calls := &atomic.Int32{}
+			ctx, announce := WithAnnounceDone(context.Background(), announceDoneExe)
+			Chain(announcingInvoker(calls), test.middlewares...).Invoke(ctx, &testFunction, &testRuntimeSpecs)
+			announce()
+
+			if calls.Load() != test.calls {
+				t.Errorf("Expected %d calls, got %d.", test.calls, calls.Load())
+			}
+
+			// exactly one of the two invocations is still to be announced
+			announceDoneExe.Done()
+			announceDoneExe.Wait()
+		})
+	}
+}
@@ -2,6 +2,7 @@ package clients
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
@@ -15,6 +16,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
 	mc "github.com/vhive-serverless/loader/pkg/metric"
 )
 
@@ -31,27 +33,41 @@ type HTTPResBody struct {
 }
 
 type openWhiskInvoker struct {
+	client                *http.Client
+	payloads              *payloadGenerator
 	announceDoneExe       *sync.WaitGroup
 	readOpenWhiskMetadata *sync.Mutex
 }
 
-func newOpenWhiskInvoker(announceDoneExe *sync.WaitGroup, readOpenWhiskMetadata *sync.Mutex) *openWhiskInvoker {
+func newOpenWhiskInvoker(cfg *config.LoaderConfiguration, announceDoneExe *sync.WaitGroup, readOpenWhiskMetadata *sync.Mutex) *openWhiskInvoker {
+	tlsConfig := NewTLSConfig(cfg)
+	if tlsConfig == nil {
+		// the OpenWhisk ingress uses a self-signed certificate by default
+		tlsConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
 	return &openWhiskInvoker{
+		client:                newPlatformHTTPClient(tlsConfig),
+		payloads:              newPayloadGenerator(cfg),
 		announceDoneExe:       announceDoneExe,
 		readOpenWhiskMetadata: readOpenWhiskMetadata,
 	}
 }
 
-func (i *openWhiskInvoker) Invoke(function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *mc.ExecutionRecord) {
+func (i *openWhiskInvoker) Invoke(ctx context.Context, function *common.Function, runtimeSpec *common.RuntimeSpecification) (bool, *mc.ExecutionRecord) {
 	log.Tracef("(Invoke)\t %s: %d[ms], %d[MiB]", function.Name, runtimeSpec.Runtime, runtimeSpec.Memory)
 
+	payload := i.payloads.payload(function)
 	qs := fmt.Sprintf("cpu=%d", runtimeSpec.Runtime)
+	if payload.ResponseSize > 0 {
+		qs += fmt.Sprintf("&response_size=%d", payload.ResponseSize)
+	}
 
-	success, executionRecordBase, res := httpInvocation(qs, function, i.announceDoneExe, true)
+	success, record, res := httpInvocation(ctx, i.client, qs, payload, function)
+	announceDone(ctx)
 	i.announceDoneExe.Wait() // To postpone querying OpenWhisk during the experiment for performance reasons (Issue 329: https://github.com/vhive-serverless/invitro/issues/329)
 
-	executionRecordBase.RequestedDuration = uint32(runtimeSpec.Runtime * 1e3)
-	record := &mc.ExecutionRecord{ExecutionRecordBase: *executionRecordBase}
+	record.RequestedDuration = uint32(runtimeSpec.Runtime * 1e3)
 	if !success {
 		return false, record
 	}
@@ -115,23 +131,28 @@ func parseActivationMetadata(response string) (error, ActivationMetadata) {
 	return nil, result
 }
 
-func httpInvocation(dataString string, function *common.Function, AnnounceDoneExe *sync.WaitGroup, tlsSkipVerify bool) (bool, *mc.ExecutionRecordBase, *http.Response) {
-	defer AnnounceDoneExe.Done()
-
-	record := &mc.ExecutionRecordBase{}
+// httpInvocation invokes a function of a public platform. The request payload, if any, is sent in the payload field
+// of a JSON body, which the platform passes to the function as a parameter.
+func httpInvocation(ctx context.Context, client *http.Client, dataString string, payload *Payload, function *common.Function) (bool, *mc.ExecutionRecord, *http.Response) {
+	record := &mc.ExecutionRecord{}
 
 	start := time.Now()
 	record.StartTime = start.UnixMicro()
 	record.Instance = function.Name
 	requestURL := function.Endpoint
-	if tlsSkipVerify {
-		http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	}
 
 	if dataString != "" {
 		requestURL += "?" + dataString
 	}
-	req, err := http.NewRequest(http.MethodGet, requestURL, bytes.NewBuffer([]byte("")))
+
+	method, requestBody := http.MethodGet, []byte("")
+	if len(payload.Request) > 0 {
+		method = http.MethodPost
+		requestBody, _ = json.Marshal(map[string][]byte{"payload": payload.Request})
+	}
+	record.RequestBytes = len(requestBody)
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, bytes.NewBuffer(requestBody))
 	if err != nil {
 		log.Warnf("http request creation failed for function %s - %s", function.Name, err)
 
@@ -143,7 +164,7 @@ func httpInvocation(dataString string, function *common.Function, AnnounceDoneEx
 
 	req.Header.Set("Content-Type", "application/json") // To avoid data being base64encoded
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		log.Debugf("http request for function %s failed - %s", function.Name, err)
 
@@ -164,6 +185,7 @@ func httpInvocation(dataString string, function *common.Function, AnnounceDoneEx
 	}
 
 	bodyBytes, err := io.ReadAll(resp.Body)
+	record.ResponseBytes = len(bodyBytes)
 	if err != nil {
 		log.Warnf("Failed to read output %s - %v", function.Name, err)
 
@@ -197,6 +219,9 @@ func httpInvocation(dataString string, function *common.Function, AnnounceDoneEx
 	record.Instance = deserializedResponse.Function
 	record.ResponseTime = time.Since(start).Microseconds()
 	record.ActualDuration = uint32(deserializedResponse.ExecutionTime)
+	if !payload.isEmpty() {
+		record.PayloadValidation = validatePayload(payload, deserializedResponse.Payload, deserializedResponse.PayloadChecksum, deserializedResponse.RequestChecksum)
+	}
 
 	return true, record, resp
 }
@@ -0,0 +1,208 @@
+package clients
+
+import (
+	"bytes"
+	"encoding/json"
+	"hash/crc32"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+)
+
+const (
+	PayloadValid   = "valid"
+	PayloadInvalid = "invalid"
+)
+
+// Payload is the payload of one invocation
+type Payload struct {
+	Request []byte
+	// ResponseSize requested from the function, which echoes the request if zero
+	ResponseSize int
+}
+
+// isEmpty tells whether the invocation carries no payload and expects none back, in which case it is not validated
+func (p *Payload) isEmpty() bool {
+	return len(p.Request) == 0 && p.ResponseSize == 0
+}
+
+// payloadSource draws the payloads of a function from a random stream of its own
+type payloadSource struct {
+	spec config.PayloadSpecification
+
+	mutex sync.Mutex
+	rand  *rand.Rand
+	// block is shared by the generated payloads, which are its prefixes
+	block []byte
+	files [][]byte
+}
+
+func newPayloadSource(spec config.PayloadSpecification, rand *rand.Rand) *payloadSource {
+	source := &payloadSource{spec: spec, rand: rand}
+	if spec.Path == "" {
+		return source
+	}
+
+	info, err := os.Stat(spec.Path)
+	if err != nil {
+		log.Fatalf("Failed to read the payload %s - %v", spec.Path, err)
+	}
+
+	paths := []string{spec.Path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(spec.Path)
+		if err != nil {
+			log.Fatalf("Failed to read the payload directory %s - %v", spec.Path, err)
+		}
+
+		paths = nil
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				paths = append(paths, filepath.Join(spec.Path, entry.Name()))
+			}
+		}
+		sort.Strings(paths)
+	}
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read the payload %s - %v", path, err)
+		}
+
+		source.files = append(source.files, content)
+	}
+	if len(source.files) == 0 {
+		log.Fatalf("Payload directory %s contains no file.", spec.Path)
+	}
+
+	return source
+}
+
+func (s *payloadSource) size() int {
+	var size float64
+	switch s.spec.SizeDistribution {
+	case "", "fixed":
+		size = float64(s.spec.SizeBytes)
+	case "uniform":
+		size = float64(s.spec.MinSizeBytes) + s.rand.Float64()*float64(s.spec.MaxSizeBytes-s.spec.MinSizeBytes+1)
+	case "exponential":
+		size = s.rand.ExpFloat64() * float64(s.spec.SizeBytes)
+	case "lognormal":
+		size = float64(s.spec.SizeBytes) * math.Exp(s.spec.SizeSigma*s.rand.NormFloat64())
+	default:
+		log.Fatalf("Unsupported payload size distribution %s.", s.spec.SizeDistribution)
+	}
+
+	size = math.Max(size, float64(s.spec.MinSizeBytes))
+	if s.spec.MaxSizeBytes > 0 {
+		size = math.Min(size, float64(s.spec.MaxSizeBytes))
+	}
+
+	return int(size)
+}
+
+func (s *payloadSource) next() *Payload {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result := &Payload{ResponseSize: s.spec.ResponseSizeBytes}
+	if len(s.files) > 0 {
+		result.Request = s.files[s.rand.Intn(len(s.files))]
+		return result
+	}
+
+	size := s.size()
+	if size > len(s.block) {
+		block := make([]byte, size)
+		copy(block, s.block)
+		s.rand.Read(block[len(s.block):])
+
+		s.block = block
+	}
+	result.Request = s.block[:size]
+
+	return result
+}
+
+// payloadGenerator hands out the payloads of the invocations of all the functions
+type payloadGenerator struct {
+	cfg  *config.PayloadConfiguration
+	seed int64
+
+	sources sync.Map
+}
+
+// newPayloadGenerator returns nil if no payload has been configured, in which case the invocations carry none
+func newPayloadGenerator(cfg *config.LoaderConfiguration) *payloadGenerator {
+	if cfg.PayloadConfigPath == "" {
+		return nil
+	}
+
+	return &payloadGenerator{
+		cfg:  config.ReadPayloadConfiguration(cfg.PayloadConfigPath),
+		seed: cfg.Seed,
+	}
+}
+
+func (g *payloadGenerator) specification(function *common.Function) config.PayloadSpecification {
+	if function.InvocationStats != nil {
+		if spec, ok := g.cfg.Functions[function.InvocationStats.HashFunction]; ok {
+			return spec
+		}
+	}
+	if spec, ok := g.cfg.Functions[function.Name]; ok {
+		return spec
+	}
+
+	return g.cfg.Default
+}
+
+// payload returns the payload of the next invocation of the function
+func (g *payloadGenerator) payload(function *common.Function) *Payload {
+	if g == nil {
+		return &Payload{}
+	}
+
+	source, ok := g.sources.Load(function.Name)
+	if !ok {
+		rand := common.NewFunctionRand(g.seed, "payload-"+function.Name)
+		source, _ = g.sources.LoadOrStore(function.Name, newPayloadSource(g.specification(function), rand))
+	}
+
+	return source.(*payloadSource).next()
+}
+
+// validatePayload checks the checksums the function has reported against the payloads that have been sent and
+// received
+func validatePayload(payload *Payload, response []byte, responseChecksum uint32, requestChecksum uint32) string {
+	expected := len(payload.Request)
+	if payload.ResponseSize > 0 {
+		expected = payload.ResponseSize
+	}
+
+	if requestChecksum != crc32.ChecksumIEEE(payload.Request) || responseChecksum != crc32.ChecksumIEEE(response) ||
+		len(response) != expected || (payload.ResponseSize == 0 && !bytes.Equal(response, payload.Request)) {
+
+		return PayloadInvalid
+	}
+
+	return PayloadValid
+}
+
+// validateResponsePayload validates the payload an HTTP function has returned in the fields of its JSON response
+func validateResponsePayload(payload *Payload, body []byte) string {
+	var response FunctionResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return PayloadInvalid
+	}
+
+	return validatePayload(payload, response.Payload, response.PayloadChecksum, response.RequestChecksum)
+}
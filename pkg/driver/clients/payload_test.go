@@ -0,0 +1,268 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	"github.com/vhive-serverless/loader/pkg/workload/proto"
+	"github.com/vhive-serverless/loader/pkg/workload/standard"
+)
+
+func writePayloadConfiguration(t *testing.T, payloads config.PayloadConfiguration) string {
+	data, err := json.Marshal(payloads)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "payload.json")
+	if err = os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestPayloadGenerator(t *testing.T) {
+	directory := t.TempDir()
+	for i, content := range []string{"first", "second"} {
+		if err := os.WriteFile(filepath.Join(directory, fmt.Sprintf("%d.txt", i)), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.LoaderConfiguration{
+		Seed: 42,
+		PayloadConfigPath: writePayloadConfiguration(t, config.PayloadConfiguration{
+			Default: config.PayloadSpecification{SizeBytes: 1024, ResponseSizeBytes: 10},
+			Functions: map[string]config.PayloadSpecification{
+				"uniform-hash": {SizeDistribution: "uniform", MinSizeBytes: 100, MaxSizeBytes: 200},
+				"lognormal":    {SizeDistribution: "lognormal", SizeBytes: 1000, SizeSigma: 1, MaxSizeBytes: 5000},
+				"directory":    {Path: directory},
+			},
+		}),
+	}
+	payloads := newPayloadGenerator(cfg)
+
+	fixed := payloads.payload(&common.Function{Name: "fixed"})
+	if len(fixed.Request) != 1024 || fixed.ResponseSize != 10 {
+		t.Errorf("Unexpected default payload of %d bytes with a response of %d bytes.", len(fixed.Request), fixed.ResponseSize)
+	}
+
+	uniform := &common.Function{Name: "uniform", InvocationStats: &common.FunctionInvocationStats{HashFunction: "uniform-hash"}}
+	for i := 0; i < 100; i++ {
+		if size := len(payloads.payload(uniform).Request); size < 100 || size > 200 {
+			t.Fatalf("Uniform payload of %d bytes out of bounds.", size)
+		}
+		if size := len(payloads.payload(&common.Function{Name: "lognormal"}).Request); size > 5000 {
+			t.Fatalf("Lognormal payload of %d bytes above the maximum.", size)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 20; i++ {
+		seen[string(payloads.payload(&common.Function{Name: "directory"}).Request)] = true
+	}
+	if len(seen) != 2 || !seen["first"] || !seen["second"] {
+		t.Errorf("Payloads should be drawn from all the files of the directory, got %v.", seen)
+	}
+
+	// the payloads of a function are reproducible across runs
+	again := newPayloadGenerator(cfg).payload(&common.Function{Name: "fixed"})
+	if !bytes.Equal(fixed.Request, again.Request) {
+		t.Error("Payloads should be derived from the seed.")
+	}
+
+	if payload := (*payloadGenerator)(nil).payload(&common.Function{Name: "fixed"}); len(payload.Request) != 0 || payload.ResponseSize != 0 {
+		t.Error("Invocations should carry no payload by default.")
+	}
+}
+
+func TestValidatePayload(t *testing.T) {
+	request := []byte("request")
+	response := []byte("0123456789")
+
+	tests := []struct {
+		name             string
+		payload          *Payload
+		response         []byte
+		responseChecksum uint32
+		requestChecksum  uint32
+		expected         string
+	}{
+		{"echo", &Payload{Request: request}, request, crc32.ChecksumIEEE(request), crc32.ChecksumIEEE(request), PayloadValid},
+		{"sized", &Payload{Request: request, ResponseSize: 10}, response, crc32.ChecksumIEEE(response), crc32.ChecksumIEEE(request), PayloadValid},
+		{"corrupted_request", &Payload{Request: request}, request, crc32.ChecksumIEEE(request), 0, PayloadInvalid},
+		{"corrupted_response", &Payload{Request: request, ResponseSize: 10}, response, 0, crc32.ChecksumIEEE(request), PayloadInvalid},
+		{"truncated_response", &Payload{Request: request, ResponseSize: 11}, response, crc32.ChecksumIEEE(response), crc32.ChecksumIEEE(request), PayloadInvalid},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := validatePayload(test.payload, test.response, test.responseChecksum, test.requestChecksum); result != test.expected {
+				t.Errorf("Got %s, expected %s.", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestGRPCClientWithPayload(t *testing.T) {
+	address, port := "localhost", 18086
+	function := common.Function{Name: "test-function", Endpoint: fmt.Sprintf("%s:%d", address, port)}
+
+	go standard.StartGRPCServer(address, port, standard.TraceFunction, "")
+	time.Sleep(2 * time.Second)
+
+	for _, responseSize := range []int{0, 64 * 1024} {
+		t.Run(fmt.Sprintf("response_%d", responseSize), func(t *testing.T) {
+			cfg := createFakeLoaderConfiguration()
+			cfg.EnableZipkinTracing = false
+			cfg.PayloadConfigPath = writePayloadConfiguration(t, config.PayloadConfiguration{
+				Default: config.PayloadSpecification{SizeBytes: 16 * 1024, ResponseSizeBytes: responseSize},
+			})
+
+			success, record := CreateInvoker(cfg, nil, nil).Invoke(context.Background(), &function, &testRuntimeSpecs)
+			if !success {
+				t.Fatal("Failed gRPC invocation with a payload.")
+			}
+
+			expectedResponse := 16 * 1024
+			if responseSize > 0 {
+				expectedResponse = responseSize
+			}
+
+			if record.PayloadValidation != PayloadValid {
+				t.Errorf("Payload should be valid, got %q.", record.PayloadValidation)
+			}
+			if record.RequestBytes < 16*1024 || record.ResponseBytes < expectedResponse {
+				t.Errorf("Unexpected message sizes - request: %d, response: %d.", record.RequestBytes, record.ResponseBytes)
+			}
+		})
+	}
+}
+
+// payloadReplyHandler replies like the HTTP workload servers, reading the payload and the requested response size from
+// the request
+func payloadReplyHandler(t *testing.T, read func(r *http.Request) *proto.FaasRequest, encode func([]byte) []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response := FunctionResponse{Status: "OK", Function: "test-function", ExecutionTime: 1000}
+		response.Payload, response.PayloadChecksum, response.RequestChecksum = proto.PayloadReply(read(r))
+
+		body, err := json.Marshal(response)
+		if err != nil {
+			t.Error(err)
+		}
+		_, _ = w.Write(encode(body))
+	}
+}
+
+func TestHTTPClientWithPayload(t *testing.T) {
+	server := httptest.NewServer(payloadReplyHandler(t, func(r *http.Request) *proto.FaasRequest {
+		body, _ := io.ReadAll(r.Body)
+		responseSize, _ := strconv.Atoi(r.Header.Get("response_size"))
+
+		return &proto.FaasRequest{Payload: body, ResponseSizeInBytes: uint32(responseSize)}
+	}, func(body []byte) []byte { return body }))
+	t.Cleanup(server.Close)
+
+	for _, responseSize := range []int{0, 4096} {
+		t.Run(fmt.Sprintf("response_%d", responseSize), func(t *testing.T) {
+			cfg := &config.LoaderConfiguration{
+				Platform:                   "Dirigent",
+				InvokeProtocol:             "http1",
+				GRPCFunctionTimeoutSeconds: 5,
+				PayloadConfigPath: writePayloadConfiguration(t, config.PayloadConfiguration{
+					Default: config.PayloadSpecification{SizeBytes: 1024, ResponseSizeBytes: responseSize},
+				}),
+			}
+
+			success, record := newHTTPInvoker(cfg).Invoke(context.Background(), &common.Function{
+				Name:             "test-function",
+				Endpoint:         strings.TrimPrefix(server.URL, "http://"),
+				DirigentMetadata: &common.DirigentMetadata{},
+			}, &testRuntimeSpecs)
+			if !success {
+				t.Fatal("Failed HTTP invocation with a payload.")
+			}
+
+			if record.PayloadValidation != PayloadValid {
+				t.Errorf("Payload should be valid, got %q.", record.PayloadValidation)
+			}
+			if record.RequestBytes != 1024 || record.ResponseBytes < common.MaxOf(responseSize, 1024) {
+				t.Errorf("Unexpected body sizes - request: %d, response: %d.", record.RequestBytes, record.ResponseBytes)
+			}
+		})
+	}
+}
+
+func TestHTTPClientWithPayloadIgnored(t *testing.T) {
+	server := startHTTPTestServer(t, 0)
+
+	cfg := &config.LoaderConfiguration{
+		Platform:                   "Dirigent",
+		InvokeProtocol:             "http1",
+		GRPCFunctionTimeoutSeconds: 5,
+		PayloadConfigPath: writePayloadConfiguration(t, config.PayloadConfiguration{
+			Default: config.PayloadSpecification{SizeBytes: 1024, ResponseSizeBytes: 4096},
+		}),
+	}
+
+	success, record := newHTTPInvoker(cfg).Invoke(context.Background(), &common.Function{
+		Name:             "test-function",
+		Endpoint:         strings.TrimPrefix(server.URL, "http://"),
+		DirigentMetadata: &common.DirigentMetadata{},
+	}, &testRuntimeSpecs)
+
+	if !success || record.PayloadValidation != PayloadInvalid {
+		t.Errorf("A reply without the payload should be invalid, got %t and %q.", success, record.PayloadValidation)
+	}
+}
+
+func TestOpenWhiskInvocationWithPayload(t *testing.T) {
+	server := httptest.NewServer(payloadReplyHandler(t, func(r *http.Request) *proto.FaasRequest {
+		if r.Method != http.MethodPost {
+			t.Errorf("Payload should be sent with POST, got %s.", r.Method)
+		}
+
+		var params struct {
+			Payload []byte `json:"payload"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			t.Error(err)
+		}
+		responseSize, _ := strconv.Atoi(r.URL.Query().Get("response_size"))
+
+		return &proto.FaasRequest{Payload: params.Payload, ResponseSizeInBytes: uint32(responseSize)}
+	}, func(body []byte) []byte { return []byte(base64.StdEncoding.EncodeToString(body)) }))
+	t.Cleanup(server.Close)
+
+	payload := &Payload{Request: bytes.Repeat([]byte{7}, 1024), ResponseSize: 4096}
+	success, record, _ := httpInvocation(context.Background(), http.DefaultClient, "cpu=10&response_size=4096", payload, &common.Function{
+		Name:     "test-function",
+		Endpoint: server.URL,
+	})
+	if !success {
+		t.Fatal("Failed OpenWhisk invocation with a payload.")
+	}
+
+	if record.PayloadValidation != PayloadValid {
+		t.Errorf("Payload should be valid, got %q.", record.PayloadValidation)
+	}
+	if record.RequestBytes <= 1024 || record.ResponseBytes <= 4096 {
+		t.Errorf("Unexpected body sizes - request: %d, response: %d.", record.RequestBytes, record.ResponseBytes)
+	}
+}
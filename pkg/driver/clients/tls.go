@@ -0,0 +1,68 @@
+package clients
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/config"
+)
+
+// NewTLSConfig creates the TLS configuration shared by the HTTP/1.1, HTTP/2 and gRPC invokers, or returns nil if the
+// invocations are sent in plain text
+func NewTLSConfig(cfg *config.LoaderConfiguration) *tls.Config {
+	if !cfg.TLSEnabled {
+		return nil
+	}
+
+	result := &tls.Config{
+		ServerName:         cfg.TLSServerName,
+		InsecureSkipVerify: cfg.TLSInsecureSkipVerify,
+	}
+
+	if cfg.TLSCACertPath != "" {
+		bundle, err := os.ReadFile(cfg.TLSCACertPath)
+		if err != nil {
+			log.Fatalf("Failed to read the CA bundle - %v", err)
+		}
+
+		result.RootCAs = x509.NewCertPool()
+		if !result.RootCAs.AppendCertsFromPEM(bundle) {
+			log.Fatalf("No certificate found in the CA bundle %s.", cfg.TLSCACertPath)
+		}
+	}
+
+	if cfg.TLSClientCertPath != "" || cfg.TLSClientKeyPath != "" {
+		certificate, err := tls.LoadX509KeyPair(cfg.TLSClientCertPath, cfg.TLSClientKeyPath)
+		if err != nil {
+			log.Fatalf("Failed to load the client certificate - %v", err)
+		}
+
+		result.Certificates = []tls.Certificate{certificate}
+	}
+
+	return result
+}
+
+// newPlatformHTTPClient creates the client of the invokers of the public platforms, whose endpoints include the scheme
+func newPlatformHTTPClient(tlsConfig *tls.Config) *http.Client {
+	if tlsConfig == nil {
+		return http.DefaultClient
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{Transport: transport}
+}
+
+// urlScheme returns the scheme of the requests of the HTTP invokers
+func urlScheme(tlsConfig *tls.Config) string {
+	if tlsConfig != nil {
+		return "https://"
+	}
+
+	return "http://"
+}
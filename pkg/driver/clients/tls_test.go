@@ -0,0 +1,268 @@
+package clients
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	"github.com/vhive-serverless/loader/pkg/workload/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// testCertificates is a self-signed CA with the server and client certificates it has issued, whose PEM files are
+// written to a temporary directory
+type testCertificates struct {
+	caPath, clientCertPath, clientKeyPath string
+
+	pool   *x509.CertPool
+	server tls.Certificate
+}
+
+func issueTestCertificate(t *testing.T, path string, template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, tls.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err = os.WriteFile(path+".crt", certPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(path+".key", keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return certificate, key, pair
+}
+
+// createTestCertificates issues a server certificate valid for localhost and test.invitro.local only
+func createTestCertificates(t *testing.T) *testCertificates {
+	directory := t.TempDir()
+	notBefore := time.Now().Add(-time.Hour)
+	notAfter := time.Now().Add(time.Hour)
+
+	ca, caKey, _ := issueTestCertificate(t, filepath.Join(directory, "ca"), &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "loader-test-ca"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil, nil)
+
+	_, _, server := issueTestCertificate(t, filepath.Join(directory, "server"), &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost", "test.invitro.local"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}, ca, caKey)
+
+	issueTestCertificate(t, filepath.Join(directory, "client"), &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "loader"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, caKey)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	return &testCertificates{
+		caPath:         filepath.Join(directory, "ca.crt"),
+		clientCertPath: filepath.Join(directory, "client.crt"),
+		clientKeyPath:  filepath.Join(directory, "client.key"),
+		pool:           pool,
+		server:         server,
+	}
+}
+
+func (c *testCertificates) serverConfig(clientAuth tls.ClientAuthType) *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{c.server},
+		ClientCAs:    c.pool,
+		ClientAuth:   clientAuth,
+	}
+}
+
+// tlsTests are run against both the HTTP and gRPC servers, which may request a client certificate
+var tlsTests = []struct {
+	name       string
+	clientAuth tls.ClientAuthType
+	configure  func(cfg *config.LoaderConfiguration, certificates *testCertificates)
+	success    bool
+}{
+	{"plain_text", tls.NoClientCert, func(cfg *config.LoaderConfiguration, _ *testCertificates) {
+		cfg.TLSEnabled = false
+	}, false},
+	{"unknown_ca", tls.NoClientCert, func(*config.LoaderConfiguration, *testCertificates) {}, false},
+	{"ca_bundle", tls.NoClientCert, func(cfg *config.LoaderConfiguration, certificates *testCertificates) {
+		cfg.TLSCACertPath = certificates.caPath
+	}, true},
+	{"skip_verify", tls.NoClientCert, func(cfg *config.LoaderConfiguration, _ *testCertificates) {
+		cfg.TLSInsecureSkipVerify = true
+	}, true},
+	{"server_name", tls.NoClientCert, func(cfg *config.LoaderConfiguration, certificates *testCertificates) {
+		cfg.TLSCACertPath = certificates.caPath
+		cfg.TLSServerName = "test.invitro.local"
+	}, true},
+	{"wrong_server_name", tls.NoClientCert, func(cfg *config.LoaderConfiguration, certificates *testCertificates) {
+		cfg.TLSCACertPath = certificates.caPath
+		cfg.TLSServerName = "unknown.invitro.local"
+	}, false},
+	{"mtls", tls.RequireAndVerifyClientCert, func(cfg *config.LoaderConfiguration, certificates *testCertificates) {
+		cfg.TLSCACertPath = certificates.caPath
+		cfg.TLSClientCertPath = certificates.clientCertPath
+		cfg.TLSClientKeyPath = certificates.clientKeyPath
+	}, true},
+	{"mtls_without_client_certificate", tls.RequireAndVerifyClientCert, func(cfg *config.LoaderConfiguration, certificates *testCertificates) {
+		cfg.TLSCACertPath = certificates.caPath
+	}, false},
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	certificates := createTestCertificates(t)
+
+	if NewTLSConfig(&config.LoaderConfiguration{TLSCACertPath: certificates.caPath}) != nil {
+		t.Error("TLS should be disabled unless enabled explicitly.")
+	}
+
+	tlsConfig := NewTLSConfig(&config.LoaderConfiguration{
+		TLSEnabled:        true,
+		TLSCACertPath:     certificates.caPath,
+		TLSClientCertPath: certificates.clientCertPath,
+		TLSClientKeyPath:  certificates.clientKeyPath,
+		TLSServerName:     "test.invitro.local",
+	})
+	if tlsConfig.RootCAs == nil || len(tlsConfig.Certificates) != 1 || tlsConfig.ServerName != "test.invitro.local" || tlsConfig.InsecureSkipVerify {
+		t.Errorf("Unexpected TLS configuration - %+v.", tlsConfig)
+	}
+}
+
+func TestHTTPClientTLS(t *testing.T) {
+	certificates := createTestCertificates(t)
+
+	for _, protocol := range []string{"http1", "http2"} {
+		for _, test := range tlsTests {
+			t.Run(protocol+"_"+test.name, func(t *testing.T) {
+				server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if protocol == "http2" && r.ProtoMajor != 2 {
+						t.Errorf("Request sent over %s instead of HTTP/2.", r.Proto)
+					}
+					_, _ = w.Write([]byte(`{"Status": "OK", "Function": "test-function", "MachineName": "test", "ExecutionTime": 1000}`))
+				}))
+				server.EnableHTTP2 = true
+				server.TLS = certificates.serverConfig(test.clientAuth)
+				server.StartTLS()
+				t.Cleanup(server.Close)
+
+				cfg := &config.LoaderConfiguration{
+					Platform:                   "Dirigent",
+					InvokeProtocol:             protocol,
+					GRPCFunctionTimeoutSeconds: 5,
+					TLSEnabled:                 true,
+				}
+				test.configure(cfg, certificates)
+
+				success, record := newHTTPInvoker(cfg).Invoke(context.Background(), &common.Function{
+					Name:             "test-function",
+					Endpoint:         strings.TrimPrefix(server.URL, "https://"),
+					DirigentMetadata: &common.DirigentMetadata{},
+				}, &testRuntimeSpecs)
+
+				if success != test.success {
+					t.Fatalf("Invocation succeeded: %t, expected %t.", success, test.success)
+				}
+				if success && record.TLSHandshakeTime == 0 {
+					t.Error("TLS handshake should have been timed.")
+				}
+			})
+		}
+	}
+}
+
+type tlsTestExecutor struct {
+	proto.UnimplementedExecutorServer
+}
+
+func (e *tlsTestExecutor) Execute(_ context.Context, req *proto.FaasRequest) (*proto.FaasReply, error) {
+	return &proto.FaasReply{Message: req.GetMessage(), DurationInMicroSec: 1000, MemoryUsageInKb: 128}, nil
+}
+
+func TestGRPCClientTLS(t *testing.T) {
+	certificates := createTestCertificates(t)
+
+	for _, test := range tlsTests {
+		t.Run(test.name, func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			server := grpc.NewServer(grpc.Creds(credentials.NewTLS(certificates.serverConfig(test.clientAuth))))
+			proto.RegisterExecutorServer(server, &tlsTestExecutor{})
+			go func() { _ = server.Serve(listener) }()
+			t.Cleanup(server.Stop)
+
+			cfg := createFakeLoaderConfiguration()
+			cfg.EnableZipkinTracing = false
+			cfg.GRPCConnectionTimeoutSeconds = 2
+			cfg.TLSEnabled = true
+			test.configure(cfg, certificates)
+
+			success, _ := CreateInvoker(cfg, nil, nil).Invoke(context.Background(), &common.Function{
+				Name:     "test-function",
+				Endpoint: listener.Addr().String(),
+			}, &testRuntimeSpecs)
+
+			if success != test.success {
+				t.Errorf("Invocation succeeded: %t, expected %t.", success, test.success)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+// closedLoopUser is a virtual client that issues its next invocation only once the previous one has returned
+type closedLoopUser struct {
+	id       int
+	workflow *common.Workflow
+	rng      *rand.Rand
+}
+
+func (d *Driver) closedLoopUsers() int {
+	if d.Configuration.LoaderConfiguration.ClosedLoopUsers > 0 {
+		return d.Configuration.LoaderConfiguration.ClosedLoopUsers
+	}
+
+	return 1
+}
+
+func (d *Driver) thinkTime(rng *rand.Rand) time.Duration {
+	mean := time.Duration(d.Configuration.LoaderConfiguration.ClosedLoopThinkTimeMs) * time.Millisecond
+
+	switch d.Configuration.LoaderConfiguration.ClosedLoopThinkTimeDistribution {
+	case "", "fixed":
+		return mean
+	case "exponential":
+		return time.Duration(rng.ExpFloat64() * float64(mean))
+	default:
+		log.Fatal("Unsupported think time distribution.")
+	}
+
+	return mean
+}
+
+// runClosedLoop starts ClosedLoopUsers virtual users for each of the root functions and keeps them busy for the given
+// duration. Each user waits for the response of its invocation and for the think time before issuing the next one.
+// Cancelling the context stops the users, while their in-flight invocations get the shutdown grace period to complete.
+func (d *Driver) runClosedLoop(ctx context.Context, duration time.Duration, workflows []*common.Workflow, totalSuccessful *int64,
+	totalFailed *int64, totalIssued *int64, recordOutputChannel chan *mc.ExecutionRecord) {
+
+	// validate the think time distribution before any user has been started
+	d.thinkTime(rand.New(rand.NewSource(d.Configuration.LoaderConfiguration.Seed)))
+
+	startOfExperiment := time.Now()
+	runCtx, cancelRun := context.WithDeadline(ctx, startOfExperiment.Add(duration))
+	defer cancelRun()
+
+	invocationCtx, cancelInvocations := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelInvocations()
+
+	log.Infof("Starting %d closed-loop users for each of the %d functions.", d.closedLoopUsers(), len(workflows))
+	if d.Configuration.WithWarmup() {
+		log.Infof("Warmup phase has started.")
+	}
+
+	usersDone := sync.WaitGroup{}
+	for i, workflow := range workflows {
+		for j := 0; j < d.closedLoopUsers(); j++ {
+			user := &closedLoopUser{
+				id:       j,
+				workflow: workflow,
+				rng:      rand.New(rand.NewSource(d.Configuration.LoaderConfiguration.Seed + int64(i*d.closedLoopUsers()+j))),
+			}
+
+			usersDone.Add(1)
+			go func() {
+				defer usersDone.Done()
+
+				d.runClosedLoopUser(runCtx, invocationCtx, user, startOfExperiment, totalSuccessful, totalFailed,
+					totalIssued, recordOutputChannel)
+			}()
+		}
+	}
+
+	d.waitForInFlightInvocations(ctx, &usersDone, cancelInvocations)
+
+	log.Debugf("All the closed-loop users have stopped.\n")
+}
+
+func (d *Driver) runClosedLoopUser(runCtx context.Context, invocationCtx context.Context, user *closedLoopUser,
+	startOfExperiment time.Time, totalSuccessful *int64, totalFailed *int64, totalIssued *int64,
+	recordOutputChannel chan *mc.ExecutionRecord) {
+
+	invocationsDone := sync.WaitGroup{}
+	defer invocationsDone.Wait()
+
+	nextInvocation := startOfExperiment
+	for invocationIndex := 0; sleepUntil(runCtx, nextInvocation); invocationIndex++ {
+		scheduledTime := time.Now()
+
+		phase := common.ExecutionPhase
+		if scheduledTime.Sub(startOfExperiment) < time.Duration(d.Configuration.LoaderConfiguration.WarmupDuration)*d.Configuration.TraceMinute() {
+			phase = common.WarmupPhase
+		}
+
+		invocationsDone.Add(1)
+		d.invokeFunction(invocationCtx, &InvocationMetadata{
+			Workflow:            user.workflow,
+			Phase:               phase,
+			InvocationID:        fmt.Sprintf("user%d.inv%d", user.id, invocationIndex),
+			IatIndex:            0,
+			ScheduledTime:       scheduledTime,
+			SuccessCount:        totalSuccessful,
+			FailedCount:         totalFailed,
+			FunctionsInvoked:    totalIssued,
+			RecordOutputChannel: recordOutputChannel,
+			AnnounceDoneWG:      &invocationsDone,
+		})
+
+		nextInvocation = time.Now().Add(d.thinkTime(user.rng))
+	}
+}
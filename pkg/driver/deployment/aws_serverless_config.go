@@ -72,7 +72,7 @@ func (s *Serverless) AddPackagePattern(pattern string) {
 
 // AddFunctionConfig adds the function configuration for serverless.com deployment
 func (s *Serverless) AddFunctionConfig(function *common.Function, provider string, awsAccountId string) {
-	// Extract trace-func-0 from trace-func-0-2642643831809466437 by splitting on "-"
+	// Extract trace-func-2642643831809466437 from the name by splitting on "-"
 	shortName := fmt.Sprintf("%s-%s", common.FunctionNamePrefix, strings.Split(function.Name, "-")[2])
 
 	var image string
@@ -0,0 +1,82 @@
+package driver
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// dispatchLagStatistics collects the dispatch lag of every invocation issued by the dispatcher, grouped by the minute
+// of the experiment in which the invocation was scheduled
+type dispatchLagStatistics struct {
+	mutex     sync.Mutex
+	perMinute map[int][]time.Duration
+}
+
+type dispatchLagSummary struct {
+	Minute      int
+	Invocations int
+	Mean        time.Duration
+	P99         time.Duration
+	Max         time.Duration
+}
+
+func newDispatchLagStatistics() *dispatchLagStatistics {
+	return &dispatchLagStatistics{
+		perMinute: make(map[int][]time.Duration),
+	}
+}
+
+func (s *dispatchLagStatistics) add(minute int, lag time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.perMinute[minute] = append(s.perMinute[minute], lag)
+}
+
+func (s *dispatchLagStatistics) summarize() []dispatchLagSummary {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var result []dispatchLagSummary
+	for minute, lags := range s.perMinute {
+		sorted := make([]time.Duration, len(lags))
+		copy(sorted, lags)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		var sum time.Duration
+		for _, lag := range sorted {
+			sum += lag
+		}
+
+		p99Index := int(float64(len(sorted))*0.99+0.5) - 1
+		p99Index = max(0, min(p99Index, len(sorted)-1))
+
+		result = append(result, dispatchLagSummary{
+			Minute:      minute,
+			Invocations: len(sorted),
+			Mean:        sum / time.Duration(len(sorted)),
+			P99:         sorted[p99Index],
+			Max:         sorted[len(sorted)-1],
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Minute < result[j].Minute })
+
+	return result
+}
+
+func (s *dispatchLagStatistics) print() {
+	log.Infof("Dispatch lag per minute of the experiment:")
+	for _, summary := range s.summarize() {
+		log.Infof("\tminute %d - invocations: %d, mean: %.2f ms, p99: %.2f ms, max: %.2f ms",
+			summary.Minute,
+			summary.Invocations,
+			float64(summary.Mean.Microseconds())/1e3,
+			float64(summary.P99.Microseconds())/1e3,
+			float64(summary.Max.Microseconds())/1e3,
+		)
+	}
+}
@@ -0,0 +1,419 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 EASL and the vHive community
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package driver
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/generator"
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+const (
+	// DefaultInvocationWorkers is the size of the invocation worker pool when none is given in the configuration
+	DefaultInvocationWorkers = 8192
+	// DefaultShutdownGracePeriod is how long in-flight invocations may take to complete once the experiment is cancelled
+	DefaultShutdownGracePeriod = 30 * time.Second
+)
+
+// functionDispatchState holds everything the dispatcher needs to know to fire the next invocation of one function
+// (or of one DAG entry function).
+type functionDispatchState struct {
+	workflow *common.Workflow
+	function *common.Function
+
+	iatIndex int
+	// fireAt is the time since the beginning of the experiment at which invocation iatIndex should be fired
+	fireAt time.Duration
+
+	// stream generates the invocations of the function while the experiment runs, in which case runtimeSpecification
+	// holds the runtime specification of invocation iatIndex
+	stream               *generator.SpecificationStream
+	runtimeSpecification *common.RuntimeSpecification
+
+	minuteIndexSearch                   *common.IntervalSearch
+	minuteIndex                         int
+	minuteIndexEnd                      int
+	invocationSinceTheBeginningOfMinute int
+	currentPhase                        common.ExperimentPhase
+}
+
+// dispatchQueue is a min-heap of function dispatch states keyed on the absolute fire time of their next invocation
+type dispatchQueue []*functionDispatchState
+
+func (q dispatchQueue) Len() int {
+	return len(q)
+}
+
+func (q dispatchQueue) Less(i, j int) bool {
+	return q[i].fireAt < q[j].fireAt
+}
+
+func (q dispatchQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *dispatchQueue) Push(x any) {
+	*q = append(*q, x.(*functionDispatchState))
+}
+
+func (q *dispatchQueue) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+
+	return item
+}
+
+// newSpecificationStream returns the stream generating the invocations of the function if the specifications are
+// generated lazily, and nil otherwise
+func (d *Driver) newSpecificationStream(function *common.Function) *generator.SpecificationStream {
+	cfg := d.Configuration.LoaderConfiguration
+	if !cfg.LazySpecification || function.ReplaySpecification {
+		return nil
+	}
+
+	return generator.NewSpecificationStream(function, d.SpecificationGenerator.ForFunction(function), d.Configuration.IATDistribution,
+		d.Configuration.ShiftIAT, d.Configuration.TraceGranularity, cfg.TimeScale, cfg.ScaleRuntimes)
+}
+
+func (d *Driver) newFunctionDispatchState(workflow *common.Workflow, stream *generator.SpecificationStream) *functionDispatchState {
+	function := workflow.Root.Function
+
+	var firstIAT float64
+	var runtimeSpecification *common.RuntimeSpecification
+	if stream != nil {
+		var ok bool
+		if firstIAT, runtimeSpecification, ok = stream.Next(); !ok {
+			log.Debugf("No invocations found for function %s.\n", function.Name)
+			return nil
+		}
+	} else {
+		if len(function.Specification.IAT) == 0 {
+			log.Debugf("No invocations found for function %s.\n", function.Name)
+			return nil
+		}
+		firstIAT = function.Specification.IAT[0]
+	}
+
+	minuteIndexSearch := common.NewIntervalSearch(function.Specification.PerMinuteCount)
+	interval := minuteIndexSearch.SearchInterval(0)
+
+	state := &functionDispatchState{
+		workflow: workflow,
+		function: function,
+
+		fireAt: time.Duration(firstIAT) * time.Microsecond,
+
+		stream:               stream,
+		runtimeSpecification: runtimeSpecification,
+
+		minuteIndexSearch: minuteIndexSearch,
+		minuteIndex:       interval.Value,
+		minuteIndexEnd:    interval.End,
+		currentPhase:      common.ExecutionPhase,
+	}
+
+	if d.Configuration.WithWarmup() {
+		state.currentPhase = common.WarmupPhase
+	}
+
+	return state
+}
+
+// advance moves the state to the next invocation and reports whether there is one left
+func (s *functionDispatchState) advance() bool {
+	s.iatIndex++
+
+	var iat float64
+	if s.stream != nil {
+		var ok bool
+		if iat, s.runtimeSpecification, ok = s.stream.Next(); !ok {
+			return false
+		}
+	} else {
+		if s.iatIndex >= len(s.function.Specification.IAT) {
+			return false
+		}
+		iat = s.function.Specification.IAT[s.iatIndex]
+	}
+
+	s.fireAt += time.Duration(iat) * time.Microsecond
+
+	s.invocationSinceTheBeginningOfMinute++
+	if s.iatIndex > s.minuteIndexEnd {
+		interval := s.minuteIndexSearch.SearchInterval(s.iatIndex)
+		if interval == nil {
+			return false
+		}
+
+		s.minuteIndexEnd, s.minuteIndex, s.invocationSinceTheBeginningOfMinute = interval.End, interval.Value, 0
+	}
+
+	return true
+}
+
+// sleepUntil blocks until the given point in time and returns false if the context has been cancelled in the meantime
+func sleepUntil(ctx context.Context, fireTime time.Time) bool {
+	sleepFor := time.Until(fireTime)
+	if sleepFor <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(sleepFor)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (d *Driver) shutdownGracePeriod() time.Duration {
+	if d.Configuration.LoaderConfiguration.ShutdownGracePeriodSeconds > 0 {
+		return time.Duration(d.Configuration.LoaderConfiguration.ShutdownGracePeriodSeconds) * time.Second
+	}
+
+	return DefaultShutdownGracePeriod
+}
+
+// waitForInFlightInvocations waits for all the issued invocations to complete. If the experiment is cancelled, the
+// in-flight invocations get at most the shutdown grace period to complete before they get cancelled as well.
+func (d *Driver) waitForInFlightInvocations(ctx context.Context, inFlight *sync.WaitGroup, cancelInvocations context.CancelFunc) {
+	allCompleted := make(chan struct{})
+	go func() {
+		inFlight.Wait()
+		close(allCompleted)
+	}()
+
+	select {
+	case <-allCompleted:
+		return
+	case <-ctx.Done():
+	}
+
+	log.Infof("Waiting up to %v for in-flight invocations to complete...", d.shutdownGracePeriod())
+
+	timer := time.NewTimer(d.shutdownGracePeriod())
+	defer timer.Stop()
+
+	select {
+	case <-allCompleted:
+	case <-timer.C:
+		log.Warnf("Cancelling the invocations that are still in flight.")
+		cancelInvocations()
+		<-allCompleted
+	}
+}
+
+// writeLoadStages writes the boundaries of the stages of the load profile, so that the results can be sliced per stage
+func (d *Driver) writeLoadStages(startOfExperiment time.Time) {
+	records := make(chan interface{}, len(d.Configuration.LoadStages))
+	for _, stage := range d.Configuration.LoadStages {
+		record := stage
+		record.StartTime += startOfExperiment.UnixMicro()
+		record.EndTime += startOfExperiment.UnixMicro()
+
+		records <- &record
+	}
+	close(records)
+
+	writerDone := sync.WaitGroup{}
+	writerDone.Add(1)
+	mc.RunCSVWriter(records, d.outputFilename("stages"), &writerDone)
+}
+
+func (d *Driver) invocationWorkerPoolSize() int {
+	if d.Configuration.LoaderConfiguration.InvocationWorkers > 0 {
+		return d.Configuration.LoaderConfiguration.InvocationWorkers
+	}
+
+	return DefaultInvocationWorkers
+}
+
+// dispatchInvocations is the single scheduler of the loader. It merges the IAT streams of all the functions into one
+// min-heap ordered by absolute fire time, sleeps until the earliest invocation is due and hands it over to a bounded
+// pool of invocation workers. The function returns once all the invocations have been issued and have completed.
+// The returned statistics hold the lag between the scheduled and the actual dispatch time of each invocation.
+// Cancelling the context stops the dispatching of new invocations.
+func (d *Driver) dispatchInvocations(ctx context.Context, workflows []*common.Workflow, addInvocationsToGroup *sync.WaitGroup, totalSuccessful *int64,
+	totalFailed *int64, totalIssued *int64, recordOutputChannel chan *mc.ExecutionRecord) *dispatchLagStatistics {
+
+	// invocations to be announced in addInvocationsToGroup by the workers once issued
+	unannounced := 0
+	queue := &dispatchQueue{}
+	for _, workflow := range workflows {
+		stream := d.newSpecificationStream(workflow.Root.Function)
+		if stream != nil {
+			for _, count := range workflow.Root.Function.Specification.PerMinuteCount {
+				unannounced += count
+			}
+		} else {
+			unannounced += len(workflow.Root.Function.Specification.IAT)
+		}
+
+		if state := d.newFunctionDispatchState(workflow, stream); state != nil {
+			*queue = append(*queue, state)
+		}
+	}
+	addInvocationsToGroup.Add(unannounced)
+	heap.Init(queue)
+
+	if d.Configuration.WithWarmup() {
+		log.Infof("Warmup phase has started.")
+	}
+
+	d.invocationMonitor = newInvocationMonitor(workflows, d.Configuration.TraceDuration, d.Configuration.TraceMinute())
+	monitorDone := make(chan struct{})
+
+	// in-flight invocations are not cancelled together with the experiment, but after a grace period
+	invocationCtx, cancelInvocations := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelInvocations()
+
+	waitForInvocations := sync.WaitGroup{}
+	invocationChannel := make(chan *InvocationMetadata, d.invocationWorkerPoolSize())
+
+	lagStatistics := newDispatchLagStatistics()
+	poolSaturationReported := false
+	startOfExperiment := time.Now()
+
+	d.invocationMonitor.startOfExperiment = startOfExperiment
+	if len(d.Configuration.LoadStages) > 0 {
+		d.writeLoadStages(startOfExperiment)
+	}
+	if d.Configuration.LoaderConfiguration.EnableRuntimeAssertions {
+		go d.monitorInvocations(d.invocationMonitor, monitorDone)
+	}
+
+	for i := 0; i < d.invocationWorkerPoolSize(); i++ {
+		go func() {
+			for metadata := range invocationChannel {
+				metadata.DispatchLag = time.Since(metadata.ScheduledTime)
+				lagStatistics.add(int(metadata.ScheduledTime.Sub(startOfExperiment)/d.Configuration.TraceMinute()), metadata.DispatchLag)
+
+				d.invokeFunction(invocationCtx, metadata)
+			}
+		}()
+	}
+
+dispatchLoop:
+	for queue.Len() > 0 {
+		state := (*queue)[0]
+
+		d.announceWarmupEnd(state.minuteIndex, &state.currentPhase)
+		if !sleepUntil(ctx, startOfExperiment.Add(state.fireAt)) {
+			log.Infof("Dispatching of invocations has been stopped.")
+			break dispatchLoop
+		}
+
+		invocationID := composeInvocationID(d.Configuration.TraceGranularity, state.minuteIndex, state.invocationSinceTheBeginningOfMinute)
+		scheduledTime := startOfExperiment.Add(state.fireAt)
+
+		if !d.Configuration.TestMode {
+			metadata := &InvocationMetadata{
+				Workflow:            state.workflow,
+				Phase:               state.currentPhase,
+				InvocationID:        invocationID,
+				IatIndex:            state.iatIndex,
+				RootSpecification:   state.runtimeSpecification,
+				ScheduledTime:       scheduledTime,
+				SuccessCount:        totalSuccessful,
+				FailedCount:         totalFailed,
+				FunctionsInvoked:    totalIssued,
+				RecordOutputChannel: recordOutputChannel,
+				AnnounceDoneWG:      &waitForInvocations,
+				AnnounceDoneExe:     addInvocationsToGroup,
+			}
+
+			waitForInvocations.Add(1)
+			if unannounced > 0 {
+				unannounced--
+			} else {
+				// the invocation is beyond those added to the group
+				metadata.AnnounceDoneExe = nil
+			}
+
+			select {
+			case invocationChannel <- metadata:
+			default:
+				if !poolSaturationReported {
+					log.Warnf("All %d invocation workers are busy. Invocations will be fired late.", d.invocationWorkerPoolSize())
+					poolSaturationReported = true
+				}
+
+				invocationChannel <- metadata
+			}
+		} else {
+			// To be used from within the Golang testing framework
+			log.Debugf("Test mode invocation fired - ID = %s.\n", invocationID)
+
+			dispatchLag := time.Since(scheduledTime)
+			lagStatistics.add(int(state.fireAt/d.Configuration.TraceMinute()), dispatchLag)
+
+			recordOutputChannel <- &mc.ExecutionRecord{
+				ExecutionRecordBase: mc.ExecutionRecordBase{
+					Phase:        int(state.currentPhase),
+					InvocationID: invocationID,
+					StartTime:    time.Now().UnixNano(),
+				},
+				ScheduledTime: scheduledTime.UnixMicro(),
+				DispatchLag:   dispatchLag.Microseconds(),
+			}
+			atomic.AddInt64(totalIssued, 1)
+			atomic.AddInt64(totalSuccessful, 1)
+		}
+
+		d.invocationMonitor.recordIssued(scheduledTime)
+
+		if state.advance() {
+			heap.Fix(queue, 0)
+		} else {
+			log.Debugf("All the invocations for function %s have been issued.\n", state.function.Name)
+			heap.Pop(queue)
+		}
+	}
+
+	// the invocations that have not been issued are announced before waiting for those in flight, which may wait for
+	// all the announcements
+	addInvocationsToGroup.Add(-unannounced)
+
+	close(monitorDone)
+	close(invocationChannel)
+	d.waitForInFlightInvocations(ctx, &waitForInvocations, cancelInvocations)
+
+	log.Debugf("All the invocations have been completed.\n")
+
+	return lagStatistics
+}
@@ -1,7 +1,9 @@
 package driver
 
 import (
+	"context"
 	"encoding/json"
+	log "github.com/sirupsen/logrus"
 	"github.com/vhive-serverless/loader/pkg/common"
 	mc "github.com/vhive-serverless/loader/pkg/metric"
 	"os"
@@ -9,7 +11,7 @@ import (
 	"time"
 )
 
-func (d *Driver) CreateMetricsScrapper(interval time.Duration,
+func (d *Driver) CreateMetricsScrapper(ctx context.Context, interval time.Duration,
 	signalReady *sync.WaitGroup, finishCh chan int, allRecordsWritten *sync.WaitGroup) func() {
 	timer := time.NewTicker(interval)
 
@@ -61,6 +63,16 @@ func (d *Driver) CreateMetricsScrapper(interval time.Duration,
 				writerDone.Wait()
 				allRecordsWritten.Done()
 
+				return
+			case <-ctx.Done():
+				log.Infof("Experiment has been cancelled. Stopping metrics scraping.")
+
+				close(knStatRecords)
+				close(scaleRecords)
+
+				writerDone.Wait()
+				allRecordsWritten.Done()
+
 				return
 			}
 		}
@@ -0,0 +1,179 @@
+package driver
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+)
+
+// invocationMonitor keeps per-minute counters of requested, issued, completed and failed invocations, which are
+// attributed to the minute in which the invocations have been scheduled. The counters are checked against the
+// runtime assertion thresholds after the end of every minute of the experiment.
+type invocationMonitor struct {
+	startOfExperiment time.Time
+	// minute is the wall-clock duration of one minute of the trace
+	minute time.Duration
+
+	requested []int64
+	issued    []int64
+	completed []int64
+	failed    []int64
+}
+
+func newInvocationMonitor(workflows []*common.Workflow, traceDuration int, minute time.Duration) *invocationMonitor {
+	requested := make([]int64, traceDuration)
+
+	for _, workflow := range workflows {
+		function := workflow.Root.Function
+
+		if len(function.Specification.IAT) == 0 {
+			// lazily generated specifications only hold the number of invocations per minute
+			for minuteIndex, count := range function.Specification.PerMinuteCount {
+				for minuteIndex >= len(requested) {
+					requested = append(requested, 0)
+				}
+				requested[minuteIndex] += int64(count)
+			}
+		}
+
+		fireAt := 0.0
+		for _, iat := range function.Specification.IAT {
+			fireAt += iat
+
+			minuteIndex := int(time.Duration(fireAt) * time.Microsecond / minute)
+			for minuteIndex >= len(requested) {
+				requested = append(requested, 0)
+			}
+			requested[minuteIndex]++
+		}
+	}
+
+	return &invocationMonitor{
+		minute:    minute,
+		requested: requested,
+		issued:    make([]int64, len(requested)),
+		completed: make([]int64, len(requested)),
+		failed:    make([]int64, len(requested)),
+	}
+}
+
+// minuteOf returns the minute of the experiment in which an invocation has been scheduled
+func (m *invocationMonitor) minuteOf(scheduledTime time.Time) int {
+	return min(max(int(scheduledTime.Sub(m.startOfExperiment)/m.minute), 0), len(m.requested)-1)
+}
+
+func (m *invocationMonitor) recordIssued(scheduledTime time.Time) {
+	atomic.AddInt64(&m.issued[m.minuteOf(scheduledTime)], 1)
+}
+
+func (m *invocationMonitor) recordCompleted(scheduledTime time.Time, success bool) {
+	minute := m.minuteOf(scheduledTime)
+
+	atomic.AddInt64(&m.completed[minute], 1)
+	if !success {
+		atomic.AddInt64(&m.failed[minute], 1)
+	}
+}
+
+// checkIssued returns a non-empty reason if the experiment should be terminated because too few of the invocations
+// scheduled in the given minute have been issued
+func (d *Driver) checkIssued(m *invocationMonitor, minute int) string {
+	requested := int(m.requested[minute])
+	issued := min(int(atomic.LoadInt64(&m.issued[minute])), requested)
+
+	log.Debugf("Minute %d - requested: %d, issued: %d", minute, requested, issued)
+
+	if !d.isRequestTargetAchieved(requested, issued, common.RequestedVsIssued) {
+		return fmt.Sprintf("Minute %d: only %d out of %d requested invocations have been issued.", minute, issued, requested)
+	}
+
+	return ""
+}
+
+// checkFailed returns a non-empty reason if the experiment should be terminated because too many of the invocations
+// scheduled in the given minute have failed
+func (d *Driver) checkFailed(m *invocationMonitor, minute int) string {
+	completed := int(atomic.LoadInt64(&m.completed[minute]))
+	succeeded := completed - int(atomic.LoadInt64(&m.failed[minute]))
+
+	log.Debugf("Minute %d - completed: %d, succeeded: %d", minute, completed, succeeded)
+
+	if !d.isRequestTargetAchieved(completed, succeeded, common.IssuedVsFailed) {
+		return fmt.Sprintf("Minute %d: %d out of %d completed invocations have failed.", minute, completed-succeeded, completed)
+	}
+
+	return ""
+}
+
+// monitorInvocations checks the issued invocations of each minute once the minute has passed, and the failed ones
+// one minute later, which leaves the invocations of the minute time to complete. It aborts the experiment when one of
+// the termination thresholds is reached, and stops once the done channel is closed.
+func (d *Driver) monitorInvocations(m *invocationMonitor, done chan struct{}) {
+	ticker := time.NewTicker(m.minute)
+	defer ticker.Stop()
+
+	for minute := 0; minute <= len(m.requested); minute++ {
+		select {
+		case <-ticker.C:
+			reason := ""
+			if minute < len(m.requested) {
+				reason = d.checkIssued(m, minute)
+			}
+			if reason == "" && minute > 0 {
+				reason = d.checkFailed(m, minute-1)
+			}
+
+			if reason != "" {
+				d.abortExperiment(reason)
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// abortExperiment cancels the experiment, which stops the dispatching of new invocations
+func (d *Driver) abortExperiment(reason string) {
+	log.Errorf("Terminating the experiment - %s", reason)
+	d.cancelExperiment(errors.New(reason))
+}
+
+// writeTerminationReason writes the reason of an early termination next to the experiment output. The presence of
+// the file marks the output CSVs as truncated.
+func (d *Driver) writeTerminationReason(reason error) {
+	err := os.WriteFile(d.outputFilenameWithExtension("termination_reason", "txt"), []byte(reason.Error()+"\n"), 0644)
+	if err != nil {
+		log.Errorf("Failed to write the termination reason - %v", err)
+	}
+}
+
+func (d *Driver) runtimeAssertionThresholds(assertType common.RuntimeAssertType) (float64, float64) {
+	cfg := d.Configuration.LoaderConfiguration
+
+	switch assertType {
+	case common.RequestedVsIssued:
+		return valueOrDefault(cfg.RequestedVsIssuedWarnThreshold, common.RequestedVsIssuedWarnThreshold),
+			valueOrDefault(cfg.RequestedVsIssuedTerminateThreshold, common.RequestedVsIssuedTerminateThreshold)
+	case common.IssuedVsFailed:
+		return valueOrDefault(cfg.FailedWarnThreshold, common.FailedWarnThreshold),
+			valueOrDefault(cfg.FailedTerminateThreshold, common.FailedTerminateThreshold)
+	default:
+		log.Fatal("Invalid type of assertion at runtime.")
+	}
+
+	return 0, 0
+}
+
+func valueOrDefault(value float64, defaultValue float64) float64 {
+	if value > 0 {
+		return value
+	}
+
+	return defaultValue
+}
@@ -0,0 +1,170 @@
+package driver
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+)
+
+// SpecificationBundleVersion is incremented whenever the layout of the bundle changes
+const SpecificationBundleVersion = 1
+
+const DefaultSpecificationBundlePath = "specification_bundle.jsonl.gz"
+
+// specificationBundleHeader is the first line of a bundle and describes the run the specifications were generated for
+type specificationBundleHeader struct {
+	Version       int    `json:"version"`
+	ConfigHash    string `json:"configHash"`
+	Seed          int64  `json:"seed"`
+	TraceDuration int    `json:"traceDuration"`
+	Functions     int    `json:"functions"`
+}
+
+// specificationBundleEntry is one line of a bundle per function, in the order of the functions of the trace
+type specificationBundleEntry struct {
+	Name          string                        `json:"name"`
+	HashOwner     string                        `json:"hashOwner"`
+	HashApp       string                        `json:"hashApp"`
+	HashFunction  string                        `json:"hashFunction"`
+	Specification *common.FunctionSpecification `json:"specification"`
+}
+
+// configurationHash identifies the configuration the specifications have been generated with. The output paths do not
+// influence the specifications, so they are left out not to prevent sharing bundles between machines.
+func configurationHash(cfg *config.LoaderConfiguration) string {
+	stripped := *cfg
+	stripped.OutputPathPrefix = ""
+	stripped.SpecificationBundlePath = ""
+
+	data, err := json.Marshal(stripped)
+	common.Check(err)
+
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func (d *Driver) specificationBundlePath() string {
+	if d.Configuration.LoaderConfiguration.SpecificationBundlePath != "" {
+		return d.Configuration.LoaderConfiguration.SpecificationBundlePath
+	}
+
+	return DefaultSpecificationBundlePath
+}
+
+func createBundleEntry(function *common.Function) *specificationBundleEntry {
+	entry := &specificationBundleEntry{
+		Name:          function.Name,
+		Specification: function.Specification,
+	}
+	if function.InvocationStats != nil {
+		entry.HashOwner = function.InvocationStats.HashOwner
+		entry.HashApp = function.InvocationStats.HashApp
+		entry.HashFunction = function.InvocationStats.HashFunction
+	}
+
+	return entry
+}
+
+// writeSpecificationBundle writes the specifications of all the functions into a single gzip-compressed JSON Lines file
+func (d *Driver) writeSpecificationBundle(path string) {
+	file, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("Failed to create the specification bundle - %v", err)
+	}
+	defer file.Close()
+
+	compressed := gzip.NewWriter(file)
+	encoder := json.NewEncoder(compressed)
+
+	err = encoder.Encode(&specificationBundleHeader{
+		Version:       SpecificationBundleVersion,
+		ConfigHash:    configurationHash(d.Configuration.LoaderConfiguration),
+		Seed:          d.Configuration.LoaderConfiguration.Seed,
+		TraceDuration: d.Configuration.TraceDuration,
+		Functions:     len(d.Configuration.Functions),
+	})
+	common.Check(err)
+
+	for _, function := range d.Configuration.Functions {
+		common.Check(encoder.Encode(createBundleEntry(function)))
+	}
+
+	if err = compressed.Close(); err != nil {
+		log.Fatalf("Failed to write the specification bundle - %v", err)
+	}
+
+	log.Infof("Specifications of %d functions have been written to %s.", len(d.Configuration.Functions), path)
+}
+
+// readSpecificationBundle loads the specifications of a bundle into the functions of the trace. The bundle must have
+// been generated for the same functions and trace duration, while a different configuration only raises a warning.
+func (d *Driver) readSpecificationBundle(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open the specification bundle - %v", err)
+	}
+	defer file.Close()
+
+	compressed, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to decompress the specification bundle - %v", err)
+	}
+	defer compressed.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(compressed))
+
+	var header specificationBundleHeader
+	if err = decoder.Decode(&header); err != nil {
+		return fmt.Errorf("failed to read the header of the specification bundle - %v", err)
+	}
+
+	if header.Version != SpecificationBundleVersion {
+		return fmt.Errorf("unsupported specification bundle version %d, expected %d", header.Version, SpecificationBundleVersion)
+	}
+	if header.Functions != len(d.Configuration.Functions) {
+		return fmt.Errorf("specification bundle contains %d functions, while the trace contains %d", header.Functions, len(d.Configuration.Functions))
+	}
+	if header.TraceDuration != d.Configuration.TraceDuration {
+		return fmt.Errorf("specification bundle covers %d minutes, while the trace covers %d", header.TraceDuration, d.Configuration.TraceDuration)
+	}
+	if header.ConfigHash != configurationHash(d.Configuration.LoaderConfiguration) {
+		log.Warnf("Specification bundle has been generated with a different configuration (seed %d).", header.Seed)
+	}
+
+	specifications := make([]*common.FunctionSpecification, len(d.Configuration.Functions))
+	for i, function := range d.Configuration.Functions {
+		var entry specificationBundleEntry
+		if err = decoder.Decode(&entry); err != nil {
+			return fmt.Errorf("failed to read the specification of function %d - %v", i, err)
+		}
+
+		expected := createBundleEntry(function)
+		if entry.Name != expected.Name || entry.HashOwner != expected.HashOwner || entry.HashApp != expected.HashApp ||
+			entry.HashFunction != expected.HashFunction {
+
+			return fmt.Errorf("function %d of the specification bundle (%s) does not match the trace (%s)", i, entry.Name, function.Name)
+		}
+		if entry.Specification == nil {
+			return fmt.Errorf("function %s has no specification in the bundle", entry.Name)
+		}
+
+		specifications[i] = entry.Specification
+	}
+
+	// the functions are only updated once the whole bundle has been validated
+	for i, function := range d.Configuration.Functions {
+		function.Specification = specifications[i]
+	}
+
+	log.Infof("Specifications of %d functions have been read from %s.", len(d.Configuration.Functions), path)
+
+	return nil
+}
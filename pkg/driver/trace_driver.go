@@ -25,11 +25,11 @@
 package driver
 
 import (
-	"container/list"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -43,6 +43,7 @@ import (
 	"github.com/vhive-serverless/loader/pkg/common"
 	"github.com/vhive-serverless/loader/pkg/generator"
 	mc "github.com/vhive-serverless/loader/pkg/metric"
+	"github.com/vhive-serverless/loader/pkg/simulator"
 	"github.com/vhive-serverless/loader/pkg/trace"
 )
 
@@ -54,6 +55,11 @@ type Driver struct {
 	AsyncRecords          *common.LockFreeQueue[*mc.ExecutionRecord]
 	readOpenWhiskMetadata sync.Mutex
 	allFunctionsInvoked   sync.WaitGroup
+
+	invocationMonitor *invocationMonitor
+	cancelExperiment  context.CancelCauseFunc
+	// workflowRecords receives a *mc.WorkflowRecord for each completed invocation of a workflow in DAG mode
+	workflowRecords chan interface{}
 }
 
 func NewDriver(driverConfig *config.Configuration) *Driver {
@@ -66,6 +72,8 @@ func NewDriver(driverConfig *config.Configuration) *Driver {
 		allFunctionsInvoked:   sync.WaitGroup{},
 	}
 
+	d.SpecificationGenerator.SetIATDistributionParameters(generator.NewIATDistributionParameters(driverConfig.LoaderConfiguration))
+	d.SpecificationGenerator.SetExecutionSpecParameters(generator.NewExecutionSpecParameters(driverConfig.LoaderConfiguration))
 	d.Invoker = clients.CreateInvoker(driverConfig.LoaderConfiguration, &d.allFunctionsInvoked, &d.readOpenWhiskMetadata)
 
 	return d
@@ -75,7 +83,40 @@ func NewDriver(driverConfig *config.Configuration) *Driver {
 // HELPER METHODS
 // ///////////////////////////////////////
 func (d *Driver) outputFilename(name string) string {
-	return fmt.Sprintf("%s_%s_%d.csv", d.Configuration.LoaderConfiguration.OutputPathPrefix, name, d.Configuration.TraceDuration)
+	return d.outputFilenameWithExtension(name, "csv")
+}
+
+func (d *Driver) outputFilenameWithExtension(name string, extension string) string {
+	return fmt.Sprintf("%s_%s_%d.%s", d.Configuration.LoaderConfiguration.OutputPathPrefix, name, d.Configuration.TraceDuration, extension)
+}
+
+// writeManifest writes the functions of the experiment together with the hashes of the trace and the resource settings
+// they have been deployed with, so that the results can be joined back to the trace
+func (d *Driver) writeManifest() {
+	records := make(chan interface{}, len(d.Configuration.Functions))
+	for _, function := range d.Configuration.Functions {
+		record := &mc.FunctionManifestRecord{
+			Function:            function.Name,
+			CPURequestsMilli:    function.CPURequestsMilli,
+			CPULimitsMilli:      function.CPULimitsMilli,
+			MemoryRequestsMiB:   function.MemoryRequestsMiB,
+			ColdStartBusyLoopMs: function.ColdStartBusyLoopMs,
+			InitialScale:        function.InitialScale,
+		}
+		if function.InvocationStats != nil {
+			record.HashOwner = function.InvocationStats.HashOwner
+			record.HashApp = function.InvocationStats.HashApp
+			record.HashFunction = function.InvocationStats.HashFunction
+			record.Trigger = function.InvocationStats.Trigger
+		}
+
+		records <- record
+	}
+	close(records)
+
+	manifestWritten := sync.WaitGroup{}
+	manifestWritten.Add(1)
+	mc.RunCSVWriter(records, d.outputFilename("manifest"), &manifestWritten)
 }
 
 /////////////////////////////////////////
@@ -83,11 +124,17 @@ func (d *Driver) outputFilename(name string) string {
 /////////////////////////////////////////
 
 type InvocationMetadata struct {
-	RootFunction *list.List
-	Phase        common.ExperimentPhase
+	Workflow *common.Workflow
+	Phase    common.ExperimentPhase
 
 	InvocationID string
 	IatIndex     int
+	// RootSpecification is the runtime specification of the root when the specifications are generated lazily
+	RootSpecification *common.RuntimeSpecification
+
+	// ScheduledTime and DispatchLag describe the root invocation and are shared by all the nodes of a DAG
+	ScheduledTime time.Time
+	DispatchLag   time.Duration
 
 	SuccessCount        *int64
 	FailedCount         *int64
@@ -112,155 +159,17 @@ func composeInvocationID(timeGranularity common.TraceGranularity, minuteIndex in
 	return fmt.Sprintf("%s%d.inv%d", timePrefix, minuteIndex, invocationIndex)
 }
 
-func (d *Driver) invokeFunction(metadata *InvocationMetadata) {
+// invokeFunction invokes all the nodes of the workflow and returns once the last of them has completed
+func (d *Driver) invokeFunction(ctx context.Context, metadata *InvocationMetadata) {
 	defer metadata.AnnounceDoneWG.Done()
 
-	var success bool
-	node := metadata.RootFunction.Front()
-	var record *mc.ExecutionRecord
-	var runtimeSpecifications *common.RuntimeSpecification
-	var branches []*list.List
-	var invocationRetries int
-	for node != nil {
-		function := node.Value.(*common.Node).Function
-		runtimeSpecifications = &function.Specification.RuntimeSpecification[metadata.IatIndex]
-
-		success, record = d.Invoker.Invoke(function, runtimeSpecifications)
-
-		if !success && (d.Configuration.LoaderConfiguration.DAGMode && invocationRetries == 0) {
-			log.Debugf("Invocation with for function %s with ID %s failed. Retrying Invocation", function.Name, metadata.InvocationID)
-			invocationRetries += 1
-			continue
-		}
-		record.Phase = int(metadata.Phase)
-		record.Instance = fmt.Sprintf("%s%s", node.Value.(*common.Node).DAG, record.Instance)
-		record.InvocationID = metadata.InvocationID
-
-		if !d.Configuration.LoaderConfiguration.AsyncMode || record.AsyncResponseID == "" {
-			metadata.RecordOutputChannel <- record
-		} else {
-			record.TimeToSubmitMs = record.ResponseTime
-			d.AsyncRecords.Enqueue(record)
-		}
-		atomic.AddInt64(metadata.FunctionsInvoked, 1)
-		if !success {
-			log.Errorf("Invocation with for function %s with ID %s failed.", function.Name, metadata.InvocationID)
-			atomic.AddInt64(metadata.FailedCount, 1)
-			break
-		}
-		atomic.AddInt64(metadata.SuccessCount, 1)
-		branches = node.Value.(*common.Node).Branches
-		for i := 0; i < len(branches); i++ {
-			newMetadataValue := *metadata
-			newMetadata := &newMetadataValue
-			newMetadata.RootFunction = branches[i]
-			newMetadata.AnnounceDoneWG.Add(1)
-			go d.invokeFunction(newMetadata)
-		}
-
-		node = node.Next()
-	}
-}
-
-func (d *Driver) functionsDriver(functionLinkedList *list.List, announceFunctionDone *sync.WaitGroup, addInvocationsToGroup *sync.WaitGroup, totalSuccessful *int64, totalFailed *int64, totalIssued *int64, recordOutputChannel chan *mc.ExecutionRecord) {
-	defer announceFunctionDone.Done()
-
-	function := functionLinkedList.Front().Value.(*common.Node).Function
-	invocationCount := len(function.Specification.IAT)
-	addInvocationsToGroup.Add(invocationCount)
-
-	if invocationCount == 0 {
-		log.Debugf("No invocations found for function %s.\n", function.Name)
-		return
+	if metadata.AnnounceDoneExe != nil {
+		var announceDone func()
+		ctx, announceDone = clients.WithAnnounceDone(ctx, metadata.AnnounceDoneExe)
+		defer announceDone()
 	}
 
-	// result statistics
-	minuteIndexSearch := common.NewIntervalSearch(function.Specification.PerMinuteCount)
-	interval := minuteIndexSearch.SearchInterval(0)
-	minuteIndexEnd, minuteIndex, invocationSinceTheBeginningOfMinute := interval.End, interval.Value, 0
-
-	IAT := function.Specification.IAT
-	iatIndex, terminationIAT := 0, invocationCount
-
-	var successfulInvocations int64
-	var failedInvocations int64
-	var functionsInvoked int64
-	var currentPhase = common.ExecutionPhase
-
-	waitForInvocations := sync.WaitGroup{}
-
-	if d.Configuration.WithWarmup() {
-		currentPhase = common.WarmupPhase
-		log.Infof("Warmup phase has started.")
-	}
-
-	startOfExperiment := time.Now()
-	var previousIATSum int64
-
-	for {
-		if iatIndex >= len(IAT) || iatIndex >= terminationIAT {
-			break // end of experiment for this individual function driver
-		}
-
-		d.announceWarmupEnd(minuteIndex, &currentPhase)
-
-		iat := time.Duration(IAT[iatIndex]) * time.Microsecond
-
-		schedulingDelay := time.Since(startOfExperiment).Microseconds() - previousIATSum
-		sleepFor := iat.Microseconds() - schedulingDelay
-		time.Sleep(time.Duration(sleepFor) * time.Microsecond)
-
-		previousIATSum += iat.Microseconds()
-
-		if !d.Configuration.TestMode {
-			waitForInvocations.Add(1)
-			go d.invokeFunction(&InvocationMetadata{
-				RootFunction:        functionLinkedList,
-				Phase:               currentPhase,
-				InvocationID:        composeInvocationID(d.Configuration.TraceGranularity, minuteIndex, invocationSinceTheBeginningOfMinute),
-				IatIndex:            iatIndex,
-				SuccessCount:        &successfulInvocations,
-				FailedCount:         &failedInvocations,
-				FunctionsInvoked:    &functionsInvoked,
-				RecordOutputChannel: recordOutputChannel,
-				AnnounceDoneWG:      &waitForInvocations,
-				AnnounceDoneExe:     addInvocationsToGroup,
-			})
-		} else {
-			// To be used from within the Golang testing framework
-			invocationID := composeInvocationID(d.Configuration.TraceGranularity, minuteIndex, invocationSinceTheBeginningOfMinute)
-			log.Debugf("Test mode invocation fired - ID = %s.\n", invocationID)
-
-			recordOutputChannel <- &mc.ExecutionRecord{
-				ExecutionRecordBase: mc.ExecutionRecordBase{
-					Phase:        int(currentPhase),
-					InvocationID: invocationID,
-					StartTime:    time.Now().UnixNano(),
-				},
-			}
-			functionsInvoked++
-			successfulInvocations++
-		}
-
-		iatIndex++
-
-		// counter updates
-		invocationSinceTheBeginningOfMinute++
-		if iatIndex > minuteIndexEnd {
-			interval = minuteIndexSearch.SearchInterval(iatIndex)
-			if interval != nil { // otherwise, the experiment will terminate in the next for loop iteration
-				minuteIndexEnd, minuteIndex, invocationSinceTheBeginningOfMinute = interval.End, interval.Value, 0
-			}
-		}
-	}
-
-	waitForInvocations.Wait()
-
-	log.Debugf("All the invocations for function %s have been completed.\n", function.Name)
-
-	atomic.AddInt64(totalSuccessful, successfulInvocations)
-	atomic.AddInt64(totalFailed, failedInvocations)
-	atomic.AddInt64(totalIssued, int64(functionsInvoked))
+	d.invokeWorkflow(ctx, metadata)
 }
 
 func (d *Driver) announceWarmupEnd(minuteIndex int, currentPhase *common.ExperimentPhase) {
@@ -270,29 +179,20 @@ func (d *Driver) announceWarmupEnd(minuteIndex int, currentPhase *common.Experim
 	}
 }
 
-// TODO: currently unused - add issued/requested monitoring feature
-func isRequestTargetAchieved(ideal int, real int, assertType common.RuntimeAssertType) bool {
+func (d *Driver) isRequestTargetAchieved(ideal int, real int, assertType common.RuntimeAssertType) bool {
 	if ideal == 0 {
 		return true
 	}
 
 	ratio := float64(ideal-real) / float64(ideal)
+	warnBound, terminationBound := d.runtimeAssertionThresholds(assertType)
 
-	var warnBound float64
-	var terminationBound float64
 	var warnMessage string
-
 	switch assertType {
 	case common.RequestedVsIssued:
-		warnBound = common.RequestedVsIssuedWarnThreshold
-		terminationBound = common.RequestedVsIssuedTerminateThreshold
 		warnMessage = fmt.Sprintf("Relative difference between requested and issued number of invocations has reached %.2f.", ratio)
 	case common.IssuedVsFailed:
-		warnBound = common.FailedWarnThreshold
-		terminationBound = common.FailedTerminateThreshold
 		warnMessage = fmt.Sprintf("Percentage of failed invocations within a minute has reached %.2f.", ratio)
-	default:
-		log.Fatal("Invalid type of assertion at runtime.")
 	}
 
 	if ratio < 0 || ratio > 1 {
@@ -312,14 +212,19 @@ func hasMinuteExpired(t1 time.Time) bool {
 	return time.Since(t1) > time.Minute
 }
 
-func (d *Driver) globalTimekeeper(totalTraceDuration int, signalReady *sync.WaitGroup) {
-	ticker := time.NewTicker(time.Minute)
+func (d *Driver) globalTimekeeper(ctx context.Context, totalTraceDuration int, signalReady *sync.WaitGroup) {
+	ticker := time.NewTicker(d.Configuration.TraceMinute())
 	globalTimeCounter := 0
 
 	signalReady.Done()
 
 	for {
-		<-ticker.C
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
 
 		log.Debugf("End of minute %d\n", globalTimeCounter)
 		globalTimeCounter++
@@ -333,7 +238,7 @@ func (d *Driver) globalTimekeeper(totalTraceDuration int, signalReady *sync.Wait
 	ticker.Stop()
 }
 
-func (d *Driver) startBackgroundProcesses(allRecordsWritten *sync.WaitGroup) (*sync.WaitGroup, chan *mc.ExecutionRecord, chan int64, chan int) {
+func (d *Driver) startBackgroundProcesses(ctx context.Context, allRecordsWritten *sync.WaitGroup) (*sync.WaitGroup, chan *mc.ExecutionRecord, chan int64, chan int) {
 	auxiliaryProcessBarrier := &sync.WaitGroup{}
 
 	finishCh := make(chan int, 1)
@@ -342,7 +247,7 @@ func (d *Driver) startBackgroundProcesses(allRecordsWritten *sync.WaitGroup) (*s
 		auxiliaryProcessBarrier.Add(1)
 
 		allRecordsWritten.Add(1)
-		metricsScrapper := d.CreateMetricsScrapper(time.Second*time.Duration(d.Configuration.LoaderConfiguration.MetricScrapingPeriodSeconds), auxiliaryProcessBarrier, finishCh, allRecordsWritten)
+		metricsScrapper := d.CreateMetricsScrapper(ctx, time.Second*time.Duration(d.Configuration.LoaderConfiguration.MetricScrapingPeriodSeconds), auxiliaryProcessBarrier, finishCh, allRecordsWritten)
 		go metricsScrapper()
 	}
 
@@ -353,58 +258,71 @@ func (d *Driver) startBackgroundProcesses(allRecordsWritten *sync.WaitGroup) (*s
 	go mc.CreateGlobalMetricsCollector(d.outputFilename("duration"), globalMetricsCollector, auxiliaryProcessBarrier, allRecordsWritten, totalIssuedChannel)
 
 	traceDurationInMinutes := d.Configuration.TraceDuration
-	go d.globalTimekeeper(traceDurationInMinutes, auxiliaryProcessBarrier)
+	go d.globalTimekeeper(ctx, traceDurationInMinutes, auxiliaryProcessBarrier)
 
 	return auxiliaryProcessBarrier, globalMetricsCollector, totalIssuedChannel, finishCh
 }
 
-func (d *Driver) internalRun() {
+func (d *Driver) internalRun(ctx context.Context) {
 	var successfulInvocations int64
 	var failedInvocations int64
 	var invocationsIssued int64
 
-	allFunctionsInvoked := sync.WaitGroup{}
-	allIndividualDriversCompleted := sync.WaitGroup{}
 	allRecordsWritten := sync.WaitGroup{}
 	allRecordsWritten.Add(1)
 
-	backgroundProcessesInitializationBarrier, globalMetricsCollector, totalIssuedChannel, scraperFinishCh := d.startBackgroundProcesses(&allRecordsWritten)
+	backgroundProcessesInitializationBarrier, globalMetricsCollector, totalIssuedChannel, scraperFinishCh := d.startBackgroundProcesses(ctx, &allRecordsWritten)
 	backgroundProcessesInitializationBarrier.Wait()
 
+	workflowRecordsWritten := sync.WaitGroup{}
+
+	var workflows []*common.Workflow
 	if d.Configuration.LoaderConfiguration.DAGMode {
-		functions := d.Configuration.Functions
-		dagLists := generator.GenerateDAGs(d.Configuration.LoaderConfiguration, functions, false)
-		log.Infof("Starting DAG invocation driver\n")
-		for i := range len(dagLists) {
-			allIndividualDriversCompleted.Add(1)
-			go d.functionsDriver(
-				dagLists[i],
-				&allIndividualDriversCompleted,
-				&allFunctionsInvoked,
-				&successfulInvocations,
-				&failedInvocations,
-				&invocationsIssued,
-				globalMetricsCollector,
-			)
+		if len(d.Configuration.Workflows) > 0 {
+			workflows = d.Configuration.Workflows
+		} else {
+			workflows = generator.GenerateDAGs(d.Configuration.LoaderConfiguration, d.Configuration.Functions, false)
 		}
+		log.Infof("Starting DAG invocation driver\n")
+
+		d.workflowRecords = make(chan interface{})
+		workflowRecordsWritten.Add(1)
+		go mc.RunCSVWriter(d.workflowRecords, d.outputFilename("workflow"), &workflowRecordsWritten)
 	} else {
 		log.Infof("Starting function invocation driver\n")
 		for _, function := range d.Configuration.Functions {
-			allIndividualDriversCompleted.Add(1)
-			functionLinkedList := list.New()
-			functionLinkedList.PushBack(&common.Node{Function: function, Depth: 0})
-			go d.functionsDriver(
-				functionLinkedList,
-				&allIndividualDriversCompleted,
-				&allFunctionsInvoked,
-				&successfulInvocations,
-				&failedInvocations,
-				&invocationsIssued,
-				globalMetricsCollector,
-			)
+			workflows = append(workflows, common.NewSingleFunctionWorkflow(function))
 		}
 	}
-	allIndividualDriversCompleted.Wait()
+
+	var dispatchLag *dispatchLagStatistics
+	if d.Configuration.ClosedLoop {
+		d.runClosedLoop(
+			ctx,
+			time.Duration(d.Configuration.TraceDuration)*d.Configuration.TraceMinute(),
+			workflows,
+			&successfulInvocations,
+			&failedInvocations,
+			&invocationsIssued,
+			globalMetricsCollector,
+		)
+	} else {
+		dispatchLag = d.dispatchInvocations(
+			ctx,
+			workflows,
+			&d.allFunctionsInvoked,
+			&successfulInvocations,
+			&failedInvocations,
+			&invocationsIssued,
+			globalMetricsCollector,
+		)
+	}
+	if d.workflowRecords != nil {
+		// all the workflows have completed once the dispatching has finished
+		close(d.workflowRecords)
+		workflowRecordsWritten.Wait()
+	}
+
 	if atomic.LoadInt64(&successfulInvocations)+atomic.LoadInt64(&failedInvocations) != 0 {
 		log.Debugf("Waiting for all the invocations record to be written.\n")
 
@@ -412,7 +330,11 @@ func (d *Driver) internalRun() {
 			sleepFor := time.Duration(d.Configuration.LoaderConfiguration.AsyncWaitToCollectMin) * time.Minute
 
 			log.Infof("Sleeping for %v...", sleepFor)
-			time.Sleep(sleepFor)
+			select {
+			case <-time.After(sleepFor):
+			case <-ctx.Done():
+				log.Warnf("Experiment has been cancelled. Collecting the responses gathered so far.")
+			}
 
 			d.writeAsyncRecordsToLog(globalMetricsCollector)
 		}
@@ -430,34 +352,47 @@ func (d *Driver) internalRun() {
 	log.Infof("Number of failed invocations: \t%d", statFailed)
 	log.Infof("Total invocations: \t\t\t%d", statSuccess+statFailed)
 	log.Infof("Failure rate: \t\t\t%.2f%%", float64(statFailed)*100.0/float64(statSuccess+statFailed))
+
+	if dispatchLag != nil {
+		dispatchLag.print()
+	}
+
+	if ctx.Err() != nil {
+		log.Warnf("The experiment has been terminated before the end of the trace.")
+		d.writeTerminationReason(context.Cause(ctx))
+	}
 }
 
 func (d *Driver) GenerateSpecification() {
 	log.Info("Generating IAT and runtime specifications for all the functions")
 
 	for i, function := range d.Configuration.Functions {
-		// Equalising all the InvocationStats to the first function
-		if d.Configuration.LoaderConfiguration.DAGMode {
-			function.InvocationStats.Invocations = d.Configuration.Functions[0].InvocationStats.Invocations
+		if d.Configuration.LoaderConfiguration.LazySpecification && !function.ReplaySpecification {
+			// the invocations are generated one minute at a time while the experiment runs
+			function.Specification = &common.FunctionSpecification{PerMinuteCount: function.InvocationStats.Invocations}
+			continue
 		}
-		spec := d.SpecificationGenerator.GenerateInvocationData(
-			function,
-			d.Configuration.IATDistribution,
-			d.Configuration.ShiftIAT,
-			d.Configuration.TraceGranularity,
-		)
 
-		d.Configuration.Functions[i].Specification = spec
-	}
-}
+		// Invocation-level traces come with the exact IATs and runtimes of the invocations
+		spec := function.Specification
+		if !function.ReplaySpecification {
+			// Equalising all the InvocationStats to the first function
+			if d.Configuration.LoaderConfiguration.DAGMode {
+				function.InvocationStats.Invocations = d.Configuration.Functions[0].InvocationStats.Invocations
+			}
+			spec = d.SpecificationGenerator.ForFunction(function).GenerateInvocationData(
+				function,
+				d.Configuration.IATDistribution,
+				d.Configuration.ShiftIAT,
+				d.Configuration.TraceGranularity,
+			)
+		}
 
-func (d *Driver) outputIATsToFile() {
-	for i, function := range d.Configuration.Functions {
-		file, _ := json.MarshalIndent(function.Specification, "", " ")
-		err := os.WriteFile("iat"+strconv.Itoa(i)+".json", file, 0644)
-		if err != nil {
-			log.Fatalf("Writing the loader config file failed: %s", err)
+		if d.Configuration.LoaderConfiguration.TimeScale > 0 {
+			generator.ScaleSpecificationTime(spec, d.Configuration.LoaderConfiguration.TimeScale, d.Configuration.LoaderConfiguration.ScaleRuntimes)
 		}
+
+		d.Configuration.Functions[i].Specification = spec
 	}
 }
 
@@ -467,33 +402,103 @@ func (d *Driver) ReadOrWriteFileSpecification(writeIATsToFile bool, readIATsFrom
 	}
 
 	if writeIATsToFile {
-		d.outputIATsToFile()
+		d.writeSpecificationBundle(d.specificationBundlePath())
 
 		log.Info("IATs have been generated. The program has exited.")
 		os.Exit(0)
 	}
 
 	if readIATsFromFile {
-		for i := range d.Configuration.Functions {
-			var spec common.FunctionSpecification
+		if err := d.readSpecificationBundle(d.specificationBundlePath()); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
 
-			iatFile, _ := os.ReadFile("iat" + strconv.Itoa(i) + ".json")
-			err := json.Unmarshal(iatFile, &spec)
-			if err != nil {
-				log.Fatalf("Failed to unmarshal IAT file: %s", err)
-			}
+// ValidateSpecification writes a report of the fidelity of the generated specifications to the trace and exits with a
+// non-zero status if any of the functions has failed the validation
+func (d *Driver) ValidateSpecification(reportPath string) {
+	if d.Configuration.LoaderConfiguration.LazySpecification {
+		log.Fatal("Lazily generated specifications cannot be validated.")
+	}
+
+	report := d.SpecificationGenerator.ValidateSpecification(d.Configuration.Functions, d.Configuration.IATDistribution,
+		d.Configuration.TraceGranularity, d.Configuration.LoaderConfiguration.TimeScale, generator.DefaultValidationThresholds())
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	common.Check(err)
+	if err = os.WriteFile(reportPath, data, 0644); err != nil {
+		log.Fatalf("Failed to write the validation report - %v", err)
+	}
 
-			d.Configuration.Functions[i].Specification = &spec
+	for _, function := range report.Functions {
+		for _, failure := range function.Failures {
+			log.Warnf("Function %s: %s.", function.Function, failure)
 		}
 	}
+
+	if !report.Passed {
+		log.Errorf("Specifications of %d out of %d functions have failed the validation. Report written to %s.",
+			report.FailedFunctions, len(report.Functions), reportPath)
+		os.Exit(1)
+	}
+
+	log.Infof("Specifications of all the %d functions have passed the validation. Report written to %s.", len(report.Functions), reportPath)
+	os.Exit(0)
 }
 
-func (d *Driver) RunExperiment() {
+// SimulateColdStarts replays the generated specifications offline under the keep-alive policies of the configuration,
+// writes the cold starts and the resource usage of each function and policy to the given CSV file and exits
+func (d *Driver) SimulateColdStarts(resultPath string) {
+	if d.Configuration.LoaderConfiguration.LazySpecification {
+		log.Fatal("Lazily generated specifications cannot be simulated.")
+	}
+
+	end := float64(d.Configuration.TraceDuration * 60)
+	if d.Configuration.LoaderConfiguration.TimeScale > 0 {
+		end /= d.Configuration.LoaderConfiguration.TimeScale
+	}
+
+	policies := simulator.NewPolicies(d.Configuration.LoaderConfiguration)
+	coldStarts := make(map[string]int)
+	instanceSeconds := make(map[string]float64)
+
+	records := make(chan interface{}, len(d.Configuration.Functions)*len(policies))
+	for _, function := range d.Configuration.Functions {
+		for _, result := range simulator.SimulateFunction(function, policies, end) {
+			coldStarts[result.Policy] += result.ColdStarts
+			instanceSeconds[result.Policy] += result.InstanceSeconds
+
+			records <- result
+		}
+	}
+	close(records)
+
+	resultWritten := sync.WaitGroup{}
+	resultWritten.Add(1)
+	mc.RunCSVWriter(records, resultPath, &resultWritten)
+
+	for _, policy := range policies {
+		log.Infof("Policy %s: %d cold starts, %.0f instance-seconds.", policy.Name(), coldStarts[policy.Name()], instanceSeconds[policy.Name()])
+	}
+
+	log.Infof("Cold starts of %d functions have been simulated. Results written to %s.", len(d.Configuration.Functions), resultPath)
+	os.Exit(0)
+}
+
+// RunExperiment deploys the functions, replays the trace and cleans up the functions at the end. Cancelling the context
+// stops issuing new invocations, gives in-flight invocations a bounded time to complete and flushes all the results
+// collected so far before cleaning up.
+func (d *Driver) RunExperiment(ctx context.Context) {
+	ctx, d.cancelExperiment = context.WithCancelCause(ctx)
+	defer d.cancelExperiment(nil)
+
 	if d.Configuration.WithWarmup() {
 		trace.DoStaticTraceProfiling(d.Configuration.Functions)
 	}
 
 	trace.ApplyResourceLimits(d.Configuration.Functions, d.Configuration.LoaderConfiguration.CPULimit)
+	d.writeManifest()
 
 	deployer := deployment.CreateDeployer(d.Configuration)
 	deployer.Deploy(d.Configuration)
@@ -501,8 +506,13 @@ func (d *Driver) RunExperiment() {
 	go failure.ScheduleFailure(d.Configuration.LoaderConfiguration.Platform, d.Configuration.FailureConfiguration)
 
 	// Generate load
-	d.internalRun()
+	d.internalRun(ctx)
 
 	// Clean up
+	if invoker, ok := d.Invoker.(io.Closer); ok {
+		if err := invoker.Close(); err != nil {
+			log.Warnf("Failed to close the invoker - %v", err)
+		}
+	}
 	deployer.Clean()
 }
@@ -25,15 +25,19 @@
 package driver
 
 import (
-	"container/list"
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"reflect"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/vhive-serverless/loader/pkg/config"
+	"github.com/vhive-serverless/loader/pkg/driver/clients"
 
 	"github.com/gocarina/gocsv"
 	"github.com/sirupsen/logrus"
@@ -141,15 +145,12 @@ func TestInvokeFunctionFromDriver(t *testing.T) {
 				time.Sleep(2 * time.Second)
 			}
 			function := testDriver.Configuration.Functions[0]
-			node := &common.Node{Function: testDriver.Configuration.Functions[0]}
-			list := list.New()
-			list.PushBack(node)
 			function.Specification.RuntimeSpecification = []common.RuntimeSpecification{{
 				Runtime: 1000,
 				Memory:  128,
 			}}
 			metadata := &InvocationMetadata{
-				RootFunction:        list,
+				Workflow:            common.NewSingleFunctionWorkflow(function),
 				Phase:               common.ExecutionPhase,
 				IatIndex:            0,
 				InvocationID:        composeInvocationID(common.MinuteGranularity, 0, 0),
@@ -161,7 +162,7 @@ func TestInvokeFunctionFromDriver(t *testing.T) {
 			}
 
 			announceDone.Add(1)
-			testDriver.invokeFunction(metadata)
+			testDriver.invokeFunction(context.Background(), metadata)
 
 			switch test.forceFail {
 			case true:
@@ -208,29 +209,14 @@ func TestDAGInvocation(t *testing.T) {
 	for i := 0; i < len(functionList); i++ {
 		functionList[i] = function
 	}
-	originalBranch := []*list.List{
-		func() *list.List {
-			l := list.New()
-			l.PushBack(&common.Node{Function: functionList[0], Depth: 0})
-			l.PushBack(&common.Node{Function: functionList[1], Depth: 1})
-			return l
-		}(),
-	}
-
-	newBranch := []*list.List{
-		func() *list.List {
-			l := list.New()
-			l.PushBack(&common.Node{Function: functionList[2], Depth: 1})
-			return l
-		}(),
-	}
-
-	rootFunction := originalBranch[0]
-	rootFunction.Front().Value.(*common.Node).Branches = newBranch
+	workflow := common.NewWorkflow("DAG 0")
+	root := workflow.AddNode("root", functionList[0])
+	workflow.AddEdge(root, workflow.AddNode("chain", functionList[1]), 1)
+	workflow.AddEdge(root, workflow.AddNode("branch", functionList[2]), 1)
 	time.Sleep(2 * time.Second)
 
 	metadata := &InvocationMetadata{
-		RootFunction:        rootFunction,
+		Workflow:            workflow,
 		Phase:               common.ExecutionPhase,
 		IatIndex:            0,
 		InvocationID:        composeInvocationID(common.MinuteGranularity, 0, 0),
@@ -242,7 +228,7 @@ func TestDAGInvocation(t *testing.T) {
 	}
 
 	announceDone.Add(1)
-	testDriver.invokeFunction(metadata)
+	testDriver.invokeFunction(context.Background(), metadata)
 	announceDone.Wait()
 	if !(successCount == 3 && failureCount == 0) {
 		t.Error("Number of successful and failed invocations not as expected.")
@@ -312,6 +298,41 @@ func TestGlobalMetricsCollector(t *testing.T) {
 	}
 }
 
+func TestWriteManifest(t *testing.T) {
+	driver := createTestDriver([]int{1})
+	function := driver.Configuration.Functions[0]
+	function.InvocationStats.HashOwner = "owner"
+	function.InvocationStats.HashApp = "app"
+	function.InvocationStats.HashFunction = "function"
+	function.CPURequestsMilli = 250
+	function.MemoryRequestsMiB = 128
+
+	driver.writeManifest()
+
+	f, err := os.Open(driver.outputFilename("manifest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var records []metric.FunctionManifestRecord
+	if err = gocsv.UnmarshalFile(f, &records); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := metric.FunctionManifestRecord{
+		Function:          function.Name,
+		HashOwner:         "owner",
+		HashApp:           "app",
+		HashFunction:      "function",
+		CPURequestsMilli:  250,
+		MemoryRequestsMiB: 128,
+	}
+	if len(records) != 1 || records[0] != expected {
+		t.Errorf("Unexpected manifest - got: %v, expected: %v.", records, expected)
+	}
+}
+
 func TestDriverBackgroundProcesses(t *testing.T) {
 	tests := []struct {
 		testName                 string
@@ -337,7 +358,7 @@ func TestDriverBackgroundProcesses(t *testing.T) {
 			driver := createTestDriver([]int{5})
 			globalCollectorAnnounceDone := &sync.WaitGroup{}
 
-			completed, _, _, _ := driver.startBackgroundProcesses(globalCollectorAnnounceDone)
+			completed, _, _, _ := driver.startBackgroundProcesses(context.Background(), globalCollectorAnnounceDone)
 
 			completed.Wait()
 		})
@@ -424,7 +445,7 @@ func TestDriverCompletely(t *testing.T) {
 			driver.Configuration.TraceGranularity = test.traceGranularity
 
 			driver.GenerateSpecification()
-			driver.RunExperiment()
+			driver.RunExperiment(context.Background())
 
 			f, err := os.Open(driver.outputFilename("duration"))
 			if err != nil {
@@ -484,6 +505,108 @@ func TestDriverCompletely(t *testing.T) {
 	}
 }
 
+func TestDispatchInvocationsOrder(t *testing.T) {
+	driver := createTestDriver([]int{3})
+
+	secondFunction := *driver.Configuration.Functions[0]
+	secondFunction.Name = "test-function-2"
+	secondFunction.Specification = &common.FunctionSpecification{
+		IAT:            []float64{5_000, 10_000},
+		PerMinuteCount: []int{2},
+	}
+	driver.Configuration.Functions[0].Specification = &common.FunctionSpecification{
+		IAT:            []float64{0, 10_000, 10_000},
+		PerMinuteCount: []int{3},
+	}
+
+	var workflows []*common.Workflow
+	for _, function := range []*common.Function{driver.Configuration.Functions[0], &secondFunction} {
+		workflows = append(workflows, common.NewSingleFunctionWorkflow(function))
+	}
+
+	var successful, failed, issued int64
+	recordOutputChannel := make(chan *metric.ExecutionRecord, 5)
+
+	driver.dispatchInvocations(context.Background(), workflows, &sync.WaitGroup{}, &successful, &failed, &issued, recordOutputChannel)
+	close(recordOutputChannel)
+
+	// function 1 fires at 0, 10 and 20 ms; function 2 fires at 5 and 15 ms
+	expectedOrder := []string{"min0.inv0", "min0.inv0", "min0.inv1", "min0.inv1", "min0.inv2"}
+
+	var previousStart int64
+	i := 0
+	for record := range recordOutputChannel {
+		if record.InvocationID != expectedOrder[i] {
+			t.Errorf("Unexpected invocation ID at position %d - got %s, expected %s.", i, record.InvocationID, expectedOrder[i])
+		}
+		if record.StartTime < previousStart {
+			t.Error("Invocations have not been dispatched in the order of their fire time.")
+		}
+		if record.ScheduledTime == 0 || record.DispatchLag < 0 {
+			t.Error("Scheduled time and dispatch lag have not been recorded.")
+		}
+
+		previousStart = record.StartTime
+		i++
+	}
+
+	if i != len(expectedOrder) || issued != int64(len(expectedOrder)) || successful != int64(len(expectedOrder)) {
+		t.Errorf("Unexpected number of invocations dispatched - got %d.", i)
+	}
+}
+
+func TestDispatchInvocationsCancellation(t *testing.T) {
+	driver := createTestDriver([]int{2})
+	driver.Configuration.Functions[0].Specification = &common.FunctionSpecification{
+		IAT:            []float64{0, 60_000_000},
+		PerMinuteCount: []int{2},
+	}
+
+	workflow := common.NewSingleFunctionWorkflow(driver.Configuration.Functions[0])
+
+	var successful, failed, issued int64
+	recordOutputChannel := make(chan *metric.ExecutionRecord, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	driver.dispatchInvocations(ctx, []*common.Workflow{workflow}, &sync.WaitGroup{}, &successful, &failed, &issued, recordOutputChannel)
+
+	if time.Since(start) > 10*time.Second {
+		t.Error("Dispatcher has not stopped after the experiment has been cancelled.")
+	}
+	if issued != 1 || len(recordOutputChannel) != 1 {
+		t.Errorf("Only the first invocation should have been issued - got %d.", issued)
+	}
+}
+
+func TestDispatchLagSummary(t *testing.T) {
+	statistics := newDispatchLagStatistics()
+	for i := 1; i <= 100; i++ {
+		statistics.add(0, time.Duration(i)*time.Millisecond)
+	}
+	statistics.add(2, 5*time.Millisecond)
+
+	summary := statistics.summarize()
+	if len(summary) != 2 {
+		t.Fatalf("Expected summaries for 2 minutes, got %d.", len(summary))
+	}
+
+	if summary[0].Minute != 0 ||
+		summary[0].Invocations != 100 ||
+		summary[0].Mean != 50500*time.Microsecond ||
+		summary[0].P99 != 99*time.Millisecond ||
+		summary[0].Max != 100*time.Millisecond {
+
+		t.Errorf("Unexpected summary for minute 0 - %+v", summary[0])
+	}
+
+	if summary[1].Minute != 2 || summary[1].Invocations != 1 || summary[1].P99 != 5*time.Millisecond {
+		t.Errorf("Unexpected summary for minute 2 - %+v", summary[1])
+	}
+}
+
 func TestHasMinuteExpired(t *testing.T) {
 	if !hasMinuteExpired(time.Now().Add(-2 * time.Minute)) {
 		t.Error("Time should have expired.")
@@ -495,27 +618,571 @@ func TestHasMinuteExpired(t *testing.T) {
 }
 
 func TestRequestedVsIssued(t *testing.T) {
-	if !isRequestTargetAchieved(100, 100*(1-common.RequestedVsIssuedWarnThreshold+0.05), common.RequestedVsIssued) {
+	driver := createTestDriver([]int{1})
+
+	if !driver.isRequestTargetAchieved(100, 100*(1-common.RequestedVsIssuedWarnThreshold+0.05), common.RequestedVsIssued) {
 		t.Error("Unexpected value received.")
 	}
 
-	if !isRequestTargetAchieved(100, 100*(1-common.RequestedVsIssuedWarnThreshold-0.05), common.RequestedVsIssued) {
+	if !driver.isRequestTargetAchieved(100, 100*(1-common.RequestedVsIssuedWarnThreshold-0.05), common.RequestedVsIssued) {
 		t.Error("Unexpected value received.")
 	}
 
-	if isRequestTargetAchieved(100, 100*(1-common.RequestedVsIssuedWarnThreshold-0.15), common.RequestedVsIssued) {
+	if driver.isRequestTargetAchieved(100, 100*(1-common.RequestedVsIssuedWarnThreshold-0.15), common.RequestedVsIssued) {
 		t.Error("Unexpected value received.")
 	}
 
-	if isRequestTargetAchieved(100, 100*(common.FailedWarnThreshold-0.1), common.IssuedVsFailed) {
+	if driver.isRequestTargetAchieved(100, 100*(common.FailedWarnThreshold-0.1), common.IssuedVsFailed) {
 		t.Error("Unexpected value received.")
 	}
 
-	if isRequestTargetAchieved(100, 100*(common.FailedWarnThreshold+0.05), common.IssuedVsFailed) {
+	if driver.isRequestTargetAchieved(100, 100*(common.FailedWarnThreshold+0.05), common.IssuedVsFailed) {
 		t.Error("Unexpected value received.")
 	}
 
-	if isRequestTargetAchieved(100, 100*(common.FailedTerminateThreshold-0.1), common.IssuedVsFailed) {
+	if driver.isRequestTargetAchieved(100, 100*(common.FailedTerminateThreshold-0.1), common.IssuedVsFailed) {
 		t.Error("Unexpected value received.")
 	}
 }
+
+func TestRuntimeAssertionThresholdsFromConfiguration(t *testing.T) {
+	driver := createTestDriver([]int{1})
+	driver.Configuration.LoaderConfiguration.FailedTerminateThreshold = 0.9
+
+	// 80% of failures is below the configured termination threshold
+	if !driver.isRequestTargetAchieved(100, 20, common.IssuedVsFailed) {
+		t.Error("Configured termination threshold has not been applied.")
+	}
+
+	if driver.isRequestTargetAchieved(100, 5, common.IssuedVsFailed) {
+		t.Error("Experiment should have been terminated.")
+	}
+}
+
+func TestInvocationMonitorAbortsExperiment(t *testing.T) {
+	driver := createTestDriver([]int{4})
+	driver.Configuration.LoaderConfiguration.OutputPathPrefix = t.TempDir() + "/test"
+	driver.Configuration.Functions[0].Specification = &common.FunctionSpecification{
+		IAT:            []float64{0, 15_000_000, 15_000_000, 15_000_000},
+		PerMinuteCount: []int{4},
+	}
+
+	workflow := common.NewSingleFunctionWorkflow(driver.Configuration.Functions[0])
+
+	monitor := newInvocationMonitor([]*common.Workflow{workflow}, 1, time.Minute)
+	monitor.startOfExperiment = time.Now()
+	if len(monitor.requested) != 1 || monitor.requested[0] != 4 {
+		t.Fatalf("Unexpected number of requested invocations - %v", monitor.requested)
+	}
+
+	for i := 0; i < 4; i++ {
+		monitor.recordIssued(monitor.startOfExperiment)
+	}
+	if reason := driver.checkIssued(monitor, 0); reason != "" {
+		t.Errorf("All the invocations have been issued, but the experiment has been terminated - %s", reason)
+	}
+
+	// completions are attributed to the minute the invocations have been scheduled in, whenever they complete
+	for i := 0; i < 4; i++ {
+		monitor.recordCompleted(monitor.startOfExperiment.Add(time.Duration(i)*15*time.Second), i == 0)
+	}
+	if monitor.completed[0] != 4 || monitor.failed[0] != 3 {
+		t.Errorf("Unexpected number of completed and failed invocations - %d and %d.", monitor.completed[0], monitor.failed[0])
+	}
+
+	reason := driver.checkFailed(monitor, 0)
+	if reason == "" {
+		t.Fatal("Failure rate of 75% should have terminated the experiment.")
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	driver.cancelExperiment = cancel
+
+	driver.abortExperiment(reason)
+	if sleepUntil(ctx, time.Now().Add(time.Hour)) {
+		t.Error("Dispatcher should not sleep once the experiment has been aborted.")
+	}
+
+	records := "phase,instance,invocationID\n1,test,min0.inv0\n"
+	if err := os.WriteFile(driver.outputFilename("duration"), []byte(records), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	driver.writeTerminationReason(context.Cause(ctx))
+	if _, err := os.Stat(driver.outputFilenameWithExtension("termination_reason", "txt")); err != nil {
+		t.Errorf("Termination reason has not been written - %v", err)
+	}
+
+	if duration, err := os.ReadFile(driver.outputFilename("duration")); err != nil || string(duration) != records {
+		t.Errorf("Duration CSV should have been left as is - got %q.", duration)
+	}
+}
+
+type closedLoopTestInvoker struct {
+	inFlight    int64
+	maxInFlight int64
+}
+
+func (i *closedLoopTestInvoker) Invoke(_ context.Context, function *common.Function, _ *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+	current := atomic.AddInt64(&i.inFlight, 1)
+	defer atomic.AddInt64(&i.inFlight, -1)
+
+	for {
+		previous := atomic.LoadInt64(&i.maxInFlight)
+		if current <= previous || atomic.CompareAndSwapInt64(&i.maxInFlight, previous, current) {
+			break
+		}
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	return true, &metric.ExecutionRecord{ExecutionRecordBase: metric.ExecutionRecordBase{Instance: function.Name}}
+}
+
+func TestClosedLoopUsers(t *testing.T) {
+	driver := createTestDriver([]int{0})
+	driver.Configuration.ClosedLoop = true
+	driver.Configuration.LoaderConfiguration.ClosedLoopUsers = 3
+	driver.Configuration.LoaderConfiguration.ClosedLoopThinkTimeMs = 5
+	driver.Configuration.Functions[0].Specification.RuntimeSpecification = []common.RuntimeSpecification{{Runtime: 10, Memory: 128}}
+
+	invoker := &closedLoopTestInvoker{}
+	driver.Invoker = invoker
+
+	workflow := common.NewSingleFunctionWorkflow(driver.Configuration.Functions[0])
+
+	var successful, failed, issued int64
+	recordOutputChannel := make(chan *metric.ExecutionRecord, 1000)
+
+	driver.runClosedLoop(context.Background(), 300*time.Millisecond, []*common.Workflow{workflow}, &successful, &failed, &issued, recordOutputChannel)
+	close(recordOutputChannel)
+
+	if invoker.maxInFlight != 3 {
+		t.Errorf("Expected 3 concurrent invocations, got %d.", invoker.maxInFlight)
+	}
+
+	// each user issues at most one invocation every 15 ms
+	if issued < 3 || issued > 3*(300/15+1) {
+		t.Errorf("Unexpected number of issued invocations - %d.", issued)
+	}
+
+	records := 0
+	for record := range recordOutputChannel {
+		if record.ScheduledTime == 0 || record.Phase != int(common.ExecutionPhase) {
+			t.Error("Closed-loop invocation has not been recorded properly.")
+		}
+		records++
+	}
+
+	if int64(records) != issued || successful != issued || failed != 0 {
+		t.Errorf("Records and counters do not match - records: %d, issued: %d, successful: %d.", records, issued, successful)
+	}
+}
+
+func TestClosedLoopWarmupWithTimeScale(t *testing.T) {
+	driver := createTestDriver([]int{0})
+	driver.Configuration.ClosedLoop = true
+	driver.Configuration.LoaderConfiguration.ClosedLoopUsers = 1
+	driver.Configuration.LoaderConfiguration.WarmupDuration = 1
+	// a minute of the trace lasts 100 ms
+	driver.Configuration.LoaderConfiguration.TimeScale = 600
+	driver.Configuration.Functions[0].Specification.RuntimeSpecification = []common.RuntimeSpecification{{Runtime: 10, Memory: 128}}
+	driver.Invoker = &closedLoopTestInvoker{}
+
+	workflow := common.NewSingleFunctionWorkflow(driver.Configuration.Functions[0])
+
+	var successful, failed, issued int64
+	recordOutputChannel := make(chan *metric.ExecutionRecord, 1000)
+
+	driver.runClosedLoop(context.Background(), 3*driver.Configuration.TraceMinute(), []*common.Workflow{workflow}, &successful, &failed, &issued, recordOutputChannel)
+	close(recordOutputChannel)
+
+	phases := make(map[int]int)
+	for record := range recordOutputChannel {
+		phases[record.Phase]++
+	}
+
+	if phases[int(common.WarmupPhase)] == 0 || phases[int(common.ExecutionPhase)] == 0 {
+		t.Errorf("Warmup should last one scaled minute - invocations per phase: %v.", phases)
+	}
+}
+
+func TestWriteLoadStages(t *testing.T) {
+	driver := createTestDriver([]int{0})
+	driver.Configuration.LoaderConfiguration.OutputPathPrefix = t.TempDir() + "/test"
+	driver.Configuration.LoadStages = []common.LoadStage{
+		{Stage: 0, Shape: "constant", StartRPS: 1, EndRPS: 1, StartTime: 0, EndTime: 60_000_000},
+		{Stage: 1, Shape: "ramp", StartRPS: 1, EndRPS: 5, StartTime: 60_000_000, EndTime: 120_000_000},
+	}
+
+	startOfExperiment := time.Now()
+	driver.writeLoadStages(startOfExperiment)
+
+	f, err := os.Open(driver.outputFilename("stages"))
+	if err != nil {
+		t.Fatalf("Stages have not been written - %v", err)
+	}
+	defer f.Close()
+
+	var stages []common.LoadStage
+	if err := gocsv.UnmarshalFile(f, &stages); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stages) != 2 || stages[1].Shape != "ramp" || stages[1].StartTime != startOfExperiment.UnixMicro()+60_000_000 {
+		t.Errorf("Unexpected stages written - %v", stages)
+	}
+}
+
+func TestDispatchInvocationsWithTimeScale(t *testing.T) {
+	driver := createTestDriver([]int{1, 1})
+	driver.Configuration.TraceDuration = 2
+	driver.Configuration.LoaderConfiguration.TimeScale = 60
+	driver.GenerateSpecification()
+
+	workflow := common.NewSingleFunctionWorkflow(driver.Configuration.Functions[0])
+
+	var successful, failed, issued int64
+	recordOutputChannel := make(chan *metric.ExecutionRecord, 2)
+
+	start := time.Now()
+	lagStatistics := driver.dispatchInvocations(context.Background(), []*common.Workflow{workflow}, &sync.WaitGroup{}, &successful, &failed, &issued, recordOutputChannel)
+	close(recordOutputChannel)
+
+	// two minutes of the trace compressed into two seconds
+	if elapsed := time.Since(start); elapsed < time.Second || elapsed > 5*time.Second {
+		t.Errorf("Unexpected replay duration - %v.", elapsed)
+	}
+
+	expectedIDs := []string{"min0.inv0", "min1.inv0"}
+	i := 0
+	for record := range recordOutputChannel {
+		if record.InvocationID != expectedIDs[i] {
+			t.Errorf("Unexpected invocation ID - got %s, expected %s.", record.InvocationID, expectedIDs[i])
+		}
+		i++
+	}
+
+	if summary := lagStatistics.summarize(); len(summary) != 2 || summary[1].Minute != 1 {
+		t.Error("Dispatch lag has not been grouped by the minutes of the trace.")
+	}
+}
+
+func TestDispatchInvocationsWithLazySpecification(t *testing.T) {
+	driver := createTestDriver([]int{3, 0, 2})
+	driver.Configuration.TraceDuration = 3
+	driver.Configuration.LoaderConfiguration.LazySpecification = true
+	driver.Configuration.LoaderConfiguration.TimeScale = 60
+	driver.GenerateSpecification()
+
+	spec := driver.Configuration.Functions[0].Specification
+	if len(spec.IAT) != 0 || len(spec.RuntimeSpecification) != 0 {
+		t.Fatal("Specification should not be generated before the experiment.")
+	}
+
+	workflow := common.NewSingleFunctionWorkflow(driver.Configuration.Functions[0])
+	if monitor := newInvocationMonitor([]*common.Workflow{workflow}, 3, driver.Configuration.TraceMinute()); monitor.requested[0] != 3 || monitor.requested[2] != 2 {
+		t.Errorf("Unexpected number of requested invocations - %v.", monitor.requested)
+	}
+
+	var successful, failed, issued int64
+	recordOutputChannel := make(chan *metric.ExecutionRecord, 5)
+
+	driver.dispatchInvocations(context.Background(), []*common.Workflow{workflow}, &sync.WaitGroup{}, &successful, &failed, &issued, recordOutputChannel)
+	close(recordOutputChannel)
+
+	expectedIDs := []string{"min0.inv0", "min0.inv1", "min0.inv2", "min2.inv0", "min2.inv1"}
+	i := 0
+	for record := range recordOutputChannel {
+		if i >= len(expectedIDs) || record.InvocationID != expectedIDs[i] {
+			t.Errorf("Unexpected invocation ID %s.", record.InvocationID)
+		}
+		i++
+	}
+	if i != len(expectedIDs) {
+		t.Errorf("Unexpected number of invocations - got %d, expected %d.", i, len(expectedIDs))
+	}
+}
+
+func TestGenerateSpecificationKeepsTraceSpecification(t *testing.T) {
+	driver := createTestDriver([]int{2})
+
+	traceSpecification := &common.FunctionSpecification{
+		IAT:                  []float64{250_000, 550_000},
+		PerMinuteCount:       []int{2},
+		RuntimeSpecification: []common.RuntimeSpecification{{Runtime: 250, Memory: 128}, {Runtime: 100, Memory: 128}},
+	}
+	driver.Configuration.Functions[0].Specification = traceSpecification
+	driver.Configuration.Functions[0].ReplaySpecification = true
+	driver.Configuration.LoaderConfiguration.TimeScale = 2
+
+	driver.GenerateSpecification()
+
+	spec := driver.Configuration.Functions[0].Specification
+	if spec != traceSpecification || spec.IAT[0] != 125_000 || spec.IAT[1] != 275_000 || spec.RuntimeSpecification[0].Runtime != 250 {
+		t.Errorf("Specification of the trace has not been replayed as is - %+v.", spec)
+	}
+}
+
+type workflowTestInvoker struct {
+	mutex     sync.Mutex
+	delay     map[string]time.Duration
+	fail      map[string]bool
+	started   map[string][]time.Time
+	completed map[string][]time.Time
+}
+
+func newWorkflowTestInvoker() *workflowTestInvoker {
+	return &workflowTestInvoker{
+		delay:     make(map[string]time.Duration),
+		fail:      make(map[string]bool),
+		started:   make(map[string][]time.Time),
+		completed: make(map[string][]time.Time),
+	}
+}
+
+func (i *workflowTestInvoker) Invoke(_ context.Context, function *common.Function, _ *common.RuntimeSpecification) (bool, *metric.ExecutionRecord) {
+	i.mutex.Lock()
+	i.started[function.Name] = append(i.started[function.Name], time.Now())
+	delay, fail := i.delay[function.Name], i.fail[function.Name]
+	i.mutex.Unlock()
+
+	time.Sleep(delay)
+
+	i.mutex.Lock()
+	i.completed[function.Name] = append(i.completed[function.Name], time.Now())
+	i.mutex.Unlock()
+
+	return !fail, &metric.ExecutionRecord{ExecutionRecordBase: metric.ExecutionRecordBase{Instance: function.Name}}
+}
+
+func createWorkflowTestDriver(functionNames ...string) (*Driver, map[string]*common.Function) {
+	driver := createTestDriver([]int{1})
+	driver.Configuration.LoaderConfiguration.DAGMode = true
+
+	functions := make(map[string]*common.Function)
+	for _, name := range functionNames {
+		functions[name] = &common.Function{
+			Name:          name,
+			Specification: &common.FunctionSpecification{RuntimeSpecification: []common.RuntimeSpecification{{Runtime: 10, Memory: 128}}},
+		}
+	}
+
+	return driver, functions
+}
+
+func invokeTestWorkflow(driver *Driver, workflow *common.Workflow) (int64, int64, *metric.WorkflowRecord) {
+	var successCount, failureCount, functionsInvoked int64
+
+	driver.workflowRecords = make(chan interface{}, 1)
+
+	announceDone := &sync.WaitGroup{}
+	announceDone.Add(1)
+	driver.invokeFunction(context.Background(), &InvocationMetadata{
+		Workflow:            workflow,
+		Phase:               common.ExecutionPhase,
+		InvocationID:        composeInvocationID(common.MinuteGranularity, 0, 0),
+		SuccessCount:        &successCount,
+		FailedCount:         &failureCount,
+		FunctionsInvoked:    &functionsInvoked,
+		RecordOutputChannel: make(chan *metric.ExecutionRecord, len(workflow.Nodes)),
+		AnnounceDoneWG:      announceDone,
+	})
+	announceDone.Wait()
+
+	return successCount, failureCount, (<-driver.workflowRecords).(*metric.WorkflowRecord)
+}
+
+func TestWorkflowJoinWaitsForAllPredecessors(t *testing.T) {
+	driver, functions := createWorkflowTestDriver("split", "fast", "slow", "merge")
+	invoker := newWorkflowTestInvoker()
+	invoker.delay["slow"] = 100 * time.Millisecond
+	driver.Invoker = invoker
+
+	workflow := common.NewWorkflow("diamond")
+	split := workflow.AddNode("split", functions["split"])
+	fast := workflow.AddNode("fast", functions["fast"])
+	slow := workflow.AddNode("slow", functions["slow"])
+	merge := workflow.AddNode("merge", functions["merge"])
+	workflow.AddEdge(split, fast, 1)
+	workflow.AddEdge(split, slow, 1)
+	workflow.AddEdge(fast, merge, 1)
+	workflow.AddEdge(slow, merge, 1)
+
+	successCount, failureCount, record := invokeTestWorkflow(driver, workflow)
+
+	if successCount != 4 || failureCount != 0 {
+		t.Errorf("Unexpected number of invocations - successful: %d, failed: %d.", successCount, failureCount)
+	}
+	if len(invoker.started["merge"]) != 1 {
+		t.Fatalf("Join node should be invoked exactly once, got %d.", len(invoker.started["merge"]))
+	}
+	if invoker.started["merge"][0].Before(invoker.completed["slow"][0]) {
+		t.Error("Join node has been invoked before all of its predecessors completed.")
+	}
+
+	if record.Workflow != "diamond" || record.NodeCount != 4 || record.WorkflowSize != 4 || record.FailedNode != "" {
+		t.Errorf("Unexpected workflow record - %+v.", record)
+	}
+	if record.CriticalPath != "split>slow>merge" {
+		t.Errorf("Unexpected critical path - got %s, expected split>slow>merge.", record.CriticalPath)
+	}
+	if record.Latency < (100*time.Millisecond).Microseconds() || record.EndTime-record.StartTime != record.Latency {
+		t.Errorf("Unexpected latency of the workflow - %d μs.", record.Latency)
+	}
+}
+
+func TestWorkflowNodeWithFewerSpecifications(t *testing.T) {
+	driver, functions := createWorkflowTestDriver("root", "replayed")
+	driver.Invoker = newWorkflowTestInvoker()
+	functions["root"].Specification.RuntimeSpecification = make([]common.RuntimeSpecification, 3)
+
+	workflow := common.NewWorkflow("chain")
+	workflow.AddEdge(workflow.AddNode("root", functions["root"]), workflow.AddNode("replayed", functions["replayed"]), 1)
+
+	var successCount, failureCount, functionsInvoked int64
+	announceDone := &sync.WaitGroup{}
+	announceDone.Add(1)
+	driver.invokeFunction(context.Background(), &InvocationMetadata{
+		Workflow:            workflow,
+		Phase:               common.ExecutionPhase,
+		InvocationID:        composeInvocationID(common.MinuteGranularity, 0, 2),
+		IatIndex:            2,
+		SuccessCount:        &successCount,
+		FailedCount:         &failureCount,
+		FunctionsInvoked:    &functionsInvoked,
+		RecordOutputChannel: make(chan *metric.ExecutionRecord, len(workflow.Nodes)),
+		AnnounceDoneWG:      announceDone,
+	})
+	announceDone.Wait()
+
+	if successCount != 2 || failureCount != 0 {
+		t.Errorf("Unexpected number of invocations - successful: %d, failed: %d.", successCount, failureCount)
+	}
+}
+
+func TestWorkflowEdgeProbabilitiesAndFailures(t *testing.T) {
+	driver, functions := createWorkflowTestDriver("root", "never", "afterNever", "always", "join", "failing", "afterFailing")
+	invoker := newWorkflowTestInvoker()
+	invoker.fail["failing"] = true
+	driver.Invoker = clients.Chain(invoker, clients.NewMiddlewares(driver.Configuration.LoaderConfiguration)...)
+
+	// root -> never (almost never taken) -> afterNever -> join
+	// root -> always -> join
+	// root -> failing -> afterFailing
+	workflow := common.NewWorkflow("conditional")
+	root := workflow.AddNode("root", functions["root"])
+	never := workflow.AddNode("never", functions["never"])
+	afterNever := workflow.AddNode("afterNever", functions["afterNever"])
+	always := workflow.AddNode("always", functions["always"])
+	join := workflow.AddNode("join", functions["join"])
+	failing := workflow.AddNode("failing", functions["failing"])
+	afterFailing := workflow.AddNode("afterFailing", functions["afterFailing"])
+	workflow.AddEdge(root, never, 1e-12)
+	workflow.AddEdge(never, afterNever, 1)
+	workflow.AddEdge(afterNever, join, 1)
+	workflow.AddEdge(root, always, 1)
+	workflow.AddEdge(always, join, 1)
+	workflow.AddEdge(root, failing, 1)
+	workflow.AddEdge(failing, afterFailing, 1)
+
+	successCount, failureCount, record := invokeTestWorkflow(driver, workflow)
+
+	// the failing node is retried once in DAG mode
+	if successCount != 3 || failureCount != 1 || len(invoker.started["failing"]) != 2 {
+		t.Errorf("Unexpected number of invocations - successful: %d, failed: %d.", successCount, failureCount)
+	}
+	if len(invoker.started["never"]) != 0 || len(invoker.started["afterNever"]) != 0 {
+		t.Error("Nodes behind an edge that has not been taken should be skipped.")
+	}
+	if len(invoker.started["join"]) != 1 {
+		t.Error("Join node should be invoked once one of its incoming edges has been taken.")
+	}
+	if len(invoker.started["afterFailing"]) != 0 {
+		t.Error("Successors of a failed node should not be invoked.")
+	}
+
+	if record.NodeCount != 4 || record.WorkflowSize != 7 || record.FailedNode != "failing" {
+		t.Errorf("Unexpected workflow record - %+v.", record)
+	}
+}
+
+func TestSpecificationBundle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.jsonl.gz")
+
+	original := createTestDriver([]int{5, 3})
+	original.Configuration.TraceDuration = 2
+	original.Configuration.LoaderConfiguration.SpecificationBundlePath = path
+	original.GenerateSpecification()
+	original.writeSpecificationBundle(original.specificationBundlePath())
+
+	loaded := createTestDriver([]int{5, 3})
+	loaded.Configuration.TraceDuration = 2
+	loaded.Configuration.LoaderConfiguration.SpecificationBundlePath = path
+	if err := loaded.readSpecificationBundle(loaded.specificationBundlePath()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(loaded.Configuration.Functions[0].Specification, original.Configuration.Functions[0].Specification) {
+		t.Errorf("Loaded specification differs from the written one - got: %v, expected: %v.",
+			loaded.Configuration.Functions[0].Specification, original.Configuration.Functions[0].Specification)
+	}
+
+	otherTrace := createTestDriver([]int{5, 3})
+	otherTrace.Configuration.TraceDuration = 2
+	otherTrace.Configuration.Functions[0].Name = "other-function"
+	if err := otherTrace.readSpecificationBundle(path); err == nil {
+		t.Error("Bundle of a different trace should not be loaded.")
+	}
+	if otherTrace.Configuration.Functions[0].Specification.IAT != nil {
+		t.Error("Functions should not be modified by a bundle that does not match the trace.")
+	}
+
+	otherDuration := createTestDriver([]int{5, 3, 1})
+	otherDuration.Configuration.TraceDuration = 3
+	if err := otherDuration.readSpecificationBundle(path); err == nil {
+		t.Error("Bundle of a different trace duration should not be loaded.")
+	}
+}
+
+func TestGenerateSpecificationIndependentOfOtherFunctions(t *testing.T) {
+	createFunction := func(hash string) *common.Function {
+		function := *createTestDriver([]int{20, 5}).Configuration.Functions[0]
+		function.Name = "function-" + hash
+		function.InvocationStats = &common.FunctionInvocationStats{HashFunction: hash, Invocations: []int{20, 5}}
+		function.Specification = nil
+
+		return &function
+	}
+
+	generate := func(hashes ...string) map[string]*common.FunctionSpecification {
+		driver := createTestDriver([]int{20, 5})
+		driver.Configuration.TraceDuration = 2
+		driver.Configuration.IATDistribution = common.Exponential
+		driver.Configuration.Functions = nil
+		for _, hash := range hashes {
+			driver.Configuration.Functions = append(driver.Configuration.Functions, createFunction(hash))
+		}
+
+		driver.GenerateSpecification()
+
+		result := make(map[string]*common.FunctionSpecification)
+		for _, function := range driver.Configuration.Functions {
+			result[function.InvocationStats.HashFunction] = function.Specification
+		}
+
+		return result
+	}
+
+	full := generate("a", "b", "c")
+	subset := generate("c", "a")
+
+	for _, hash := range []string{"a", "c"} {
+		if !reflect.DeepEqual(full[hash], subset[hash]) {
+			t.Errorf("Specification of function %s depends on the other functions of the trace.", hash)
+		}
+	}
+	if reflect.DeepEqual(full["a"].IAT, full["b"].IAT) {
+		t.Error("Functions should not share the same random stream.")
+	}
+}
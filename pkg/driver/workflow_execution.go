@@ -0,0 +1,221 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	mc "github.com/vhive-serverless/loader/pkg/metric"
+)
+
+// workflowExecution holds the state of one invocation of a workflow. A node is invoked once all of its incoming edges
+// have been resolved and at least one of them has been taken. A node whose incoming edges have all been resolved
+// without being taken is skipped, while a node that depends on a failed node fails as well. In both cases the outcome
+// is propagated to the successors of the node without invoking it.
+type workflowExecution struct {
+	metadata *InvocationMetadata
+
+	mutex      sync.Mutex
+	unresolved map[*common.WorkflowNode]int
+	taken      map[*common.WorkflowNode]bool
+	failed     map[*common.WorkflowNode]bool
+	rng        *rand.Rand
+
+	// startTime and endTime of the invoked nodes, used to find the critical path of the execution
+	startTime map[*common.WorkflowNode]time.Time
+	endTime   map[*common.WorkflowNode]time.Time
+	// criticalPredecessor is the last predecessor that has completed before the node got invoked
+	criticalPredecessor map[*common.WorkflowNode]*common.WorkflowNode
+	failedNode          *common.WorkflowNode
+
+	nodesDone sync.WaitGroup
+}
+
+func (d *Driver) newWorkflowExecution(metadata *InvocationMetadata) *workflowExecution {
+	execution := &workflowExecution{
+		metadata:   metadata,
+		unresolved: make(map[*common.WorkflowNode]int, len(metadata.Workflow.Nodes)),
+		taken:      make(map[*common.WorkflowNode]bool, len(metadata.Workflow.Nodes)),
+		failed:     make(map[*common.WorkflowNode]bool, len(metadata.Workflow.Nodes)),
+
+		startTime:           make(map[*common.WorkflowNode]time.Time, len(metadata.Workflow.Nodes)),
+		endTime:             make(map[*common.WorkflowNode]time.Time, len(metadata.Workflow.Nodes)),
+		criticalPredecessor: make(map[*common.WorkflowNode]*common.WorkflowNode, len(metadata.Workflow.Nodes)),
+	}
+
+	for _, node := range metadata.Workflow.Nodes {
+		execution.unresolved[node] = len(node.Predecessors)
+	}
+
+	return execution
+}
+
+// takeEdge decides whether the edge is followed after its predecessor has completed
+func (d *Driver) takeEdge(execution *workflowExecution, edge *common.WorkflowEdge) bool {
+	if edge.Probability >= 1 {
+		return true
+	}
+
+	execution.mutex.Lock()
+	defer execution.mutex.Unlock()
+
+	if execution.rng == nil {
+		execution.rng = rand.New(rand.NewSource(d.Configuration.LoaderConfiguration.Seed + int64(execution.metadata.IatIndex)))
+	}
+
+	return execution.rng.Float64() < edge.Probability
+}
+
+// resolveEdge marks the incoming edge of a node as resolved and reports whether all the incoming edges of the node
+// have been resolved, whether any of them has been taken and whether any of the predecessors has failed
+func (e *workflowExecution) resolveEdge(edge *common.WorkflowEdge, taken bool, failed bool) (bool, bool, bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.unresolved[edge.To]--
+	if taken {
+		e.taken[edge.To] = true
+		e.criticalPredecessor[edge.To] = edge.From
+	}
+	if failed {
+		e.failed[edge.To] = true
+	}
+
+	return e.unresolved[edge.To] == 0, e.taken[edge.To], e.failed[edge.To]
+}
+
+func (d *Driver) invokeWorkflow(ctx context.Context, metadata *InvocationMetadata) {
+	execution := d.newWorkflowExecution(metadata)
+
+	execution.nodesDone.Add(1)
+	d.invokeWorkflowNode(ctx, execution, metadata.Workflow.Root)
+
+	execution.nodesDone.Wait()
+
+	if d.workflowRecords != nil {
+		d.workflowRecords <- execution.createRecord()
+	}
+}
+
+// recordNode stores the time interval of an invoked node
+func (e *workflowExecution) recordNode(node *common.WorkflowNode, start time.Time, end time.Time, success bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.startTime[node] = start
+	e.endTime[node] = end
+	if !success && (e.failedNode == nil || end.Before(e.endTime[e.failedNode])) {
+		e.failedNode = node
+	}
+}
+
+// createRecord summarizes a completed execution. The critical path is found by walking back from the node that has
+// completed last through the predecessors that have released each of the nodes on the path.
+func (e *workflowExecution) createRecord() *mc.WorkflowRecord {
+	metadata := e.metadata
+	workflow := metadata.Workflow
+
+	var last *common.WorkflowNode
+	for node, end := range e.endTime {
+		if last == nil || end.After(e.endTime[last]) {
+			last = node
+		}
+	}
+
+	var criticalPath []string
+	for node := last; node != nil; node = e.criticalPredecessor[node] {
+		criticalPath = append([]string{node.Function.Name}, criticalPath...)
+	}
+
+	record := &mc.WorkflowRecord{
+		Phase:         int(metadata.Phase),
+		Workflow:      workflow.Name,
+		InvocationID:  metadata.InvocationID,
+		ScheduledTime: metadata.ScheduledTime.UnixMicro(),
+		StartTime:     e.startTime[workflow.Root].UnixMicro(),
+		EndTime:       e.endTime[last].UnixMicro(),
+		NodeCount:     len(e.endTime),
+		WorkflowSize:  len(workflow.Nodes),
+		CriticalPath:  strings.Join(criticalPath, ">"),
+	}
+	record.Latency = record.EndTime - record.StartTime
+	if e.failedNode != nil {
+		record.FailedNode = e.failedNode.Name
+	}
+
+	return record
+}
+
+func (d *Driver) invokeWorkflowNode(ctx context.Context, execution *workflowExecution, node *common.WorkflowNode) {
+	defer execution.nodesDone.Done()
+
+	metadata := execution.metadata
+	function := node.Function
+	var runtimeSpecifications *common.RuntimeSpecification
+	specifications := function.Specification.RuntimeSpecification
+	if node == metadata.Workflow.Root && metadata.RootSpecification != nil {
+		runtimeSpecifications = metadata.RootSpecification
+	} else if len(specifications) > 0 {
+		// the specifications of a node are not necessarily as many as those of the root
+		runtimeSpecifications = &specifications[metadata.IatIndex%len(specifications)]
+	} else {
+		runtimeSpecifications = &metadata.Workflow.Root.Function.Specification.RuntimeSpecification[metadata.IatIndex]
+	}
+
+	start := time.Now()
+	success, record := d.Invoker.Invoke(ctx, function, runtimeSpecifications)
+	execution.recordNode(node, start, time.Now(), success)
+
+	record.Phase = int(metadata.Phase)
+	if metadata.Workflow.Name != "" {
+		record.Instance = fmt.Sprintf("%s,%s", metadata.Workflow.Name, record.Instance)
+	}
+	record.InvocationID = metadata.InvocationID
+	record.ScheduledTime = metadata.ScheduledTime.UnixMicro()
+	record.DispatchLag = metadata.DispatchLag.Microseconds()
+
+	if !d.Configuration.LoaderConfiguration.AsyncMode || record.AsyncResponseID == "" {
+		metadata.RecordOutputChannel <- record
+	} else {
+		record.TimeToSubmitMs = record.ResponseTime
+		d.AsyncRecords.Enqueue(record)
+	}
+	atomic.AddInt64(metadata.FunctionsInvoked, 1)
+	if d.invocationMonitor != nil {
+		d.invocationMonitor.recordCompleted(metadata.ScheduledTime, success)
+	}
+
+	if !success {
+		log.Errorf("Invocation with for function %s with ID %s failed.", function.Name, metadata.InvocationID)
+		atomic.AddInt64(metadata.FailedCount, 1)
+	} else {
+		atomic.AddInt64(metadata.SuccessCount, 1)
+	}
+
+	d.resolveSuccessors(ctx, execution, node, success, !success)
+}
+
+// resolveSuccessors follows the outgoing edges of a node. Edges of failed or skipped nodes are never taken.
+func (d *Driver) resolveSuccessors(ctx context.Context, execution *workflowExecution, node *common.WorkflowNode, completed bool, failed bool) {
+	for _, edge := range node.Successors {
+		taken := completed && d.takeEdge(execution, edge)
+
+		ready, anyTaken, anyFailed := execution.resolveEdge(edge, taken, failed)
+		if !ready {
+			continue
+		}
+
+		if anyTaken && !anyFailed {
+			execution.nodesDone.Add(1)
+			go d.invokeWorkflowNode(ctx, execution, edge.To)
+		} else {
+			d.resolveSuccessors(ctx, execution, edge.To, false, anyFailed)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+)
+
+// CreateClosedLoopFunctions creates the functions used in the closed-loop mode. The number of invocations is not
+// known in advance, as it depends on the response time of the functions, so each function has a single runtime
+// specification that is used by all its invocations.
+func CreateClosedLoopFunctions(cfg *config.LoaderConfiguration) []*common.Function {
+	var result []*common.Function
+
+	functionCount := cfg.ClosedLoopFunctions
+	if functionCount <= 0 {
+		functionCount = 1
+	}
+
+	for i := 0; i < functionCount; i++ {
+		result = append(result, &common.Function{
+			Name: fmt.Sprintf("closed-loop-function-%d-%d", i, common.NewFunctionRand(cfg.Seed, fmt.Sprintf("closed-loop-function-%d", i)).Int()),
+
+			InvocationStats: &common.FunctionInvocationStats{},
+			RuntimeStats:    &common.FunctionRuntimeStats{Average: float64(cfg.RpsRuntimeMs)},
+			MemoryStats:     &common.FunctionMemoryStats{Percentile100: float64(cfg.RpsMemoryMB)},
+			DirigentMetadata: &common.DirigentMetadata{
+				Image:               cfg.RpsImage,
+				Port:                80,
+				Protocol:            "tcp",
+				ScalingUpperBound:   1024,
+				ScalingLowerBound:   1,
+				IterationMultiplier: cfg.RpsIterationMultiplier,
+				IOPercentage:        0,
+			},
+
+			Specification: &common.FunctionSpecification{
+				RuntimeSpecification: createRuntimeSpecification(1, cfg.RpsRuntimeMs, cfg.RpsMemoryMB),
+			},
+
+			ColdStartBusyLoopMs: ComputeBusyLoopPeriod(cfg.RpsMemoryMB),
+		})
+	}
+
+	return result
+}
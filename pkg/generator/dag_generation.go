@@ -1,14 +1,12 @@
 package generator
 
 import (
-	"container/list"
 	"encoding/csv"
 	"fmt"
 	"math/rand"
 	"os"
 	"strconv"
 	"strings"
-	"sync/atomic"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/vhive-serverless/loader/pkg/common"
@@ -16,46 +14,19 @@ import (
 )
 
 // Visual Representation for the DAG
-func printDAG(DAGWorkflow *list.List) {
-	DAGNode := DAGWorkflow.Front()
-	nodeQueue := make([]*list.Element, 0)
-	nodeQueue = append(nodeQueue, DAGNode)
-	var printMessage string
-	var buffer string = ""
-	var dummyNode *list.Element
-	var startingNode bool = true
-	for len(nodeQueue) > 0 {
-		DAGNode = nodeQueue[0]
-		nodeQueue = nodeQueue[1:]
-		functionId := common.GetName(DAGNode.Value.(*common.Node).Function)
-		if startingNode {
-			printMessage = "|" + strconv.Itoa(functionId)
-			for i := 0; i < DAGNode.Value.(*common.Node).Depth; i++ {
-				buffer += "     "
-			}
-			printMessage = buffer + printMessage
-			startingNode = false
-		} else {
-			printMessage = printMessage + " -> " + strconv.Itoa(functionId)
-		}
-		for i := 0; i < len(DAGNode.Value.(*common.Node).Branches); i++ {
-			nodeQueue = append(nodeQueue, dummyNode)
-			copy(nodeQueue[1:], nodeQueue)
-			nodeQueue[0] = DAGNode.Value.(*common.Node).Branches[i].Front()
+func printDAG(workflow *common.Workflow) {
+	for _, node := range workflow.Nodes {
+		printMessage := strings.Repeat("     ", node.Depth) + "|" + node.Function.Name
+
+		var successors []string
+		for _, edge := range node.Successors {
+			successors = append(successors, edge.To.Function.Name)
 		}
-		if DAGNode.Next() == nil {
-			println(printMessage)
-			buffer = ""
-			if len(nodeQueue) > 0 {
-				startingNode = true
-			} else {
-				break
-			}
-		} else {
-			nodeQueue = append(nodeQueue, dummyNode)
-			copy(nodeQueue[1:], nodeQueue)
-			nodeQueue[0] = DAGNode.Next()
+		if len(successors) > 0 {
+			printMessage += " -> " + strings.Join(successors, ", ")
 		}
+
+		println(printMessage)
 	}
 }
 
@@ -119,12 +90,12 @@ func getDAGStats(cdf [][]float64, maxSize int, numberOfTries int) (int, int) {
 	return width, depth
 }
 
-func GenerateDAGs(config *config.LoaderConfiguration, functions []*common.Function, test bool) []*list.List {
+func GenerateDAGs(config *config.LoaderConfiguration, functions []*common.Function, test bool) []*common.Workflow {
 	var width, depth int
 	var functionIndex int = 0
 	var dagIdentity int = 0
-	var functionLinkedList *list.List
-	totalDAGList := []*list.List{}
+	var workflow *common.Workflow
+	totalDAGList := []*common.Workflow{}
 	for {
 		if config.EnableDAGDataset {
 			DAGDistribution := generateCDF(fmt.Sprintf("%s/dag_structure.csv", config.TracePath))
@@ -141,62 +112,60 @@ func GenerateDAGs(config *config.LoaderConfiguration, functions []*common.Functi
 			log.Infof("DAGs created: %d, Total Functions used: %d, Functions Unused: %d", dagIdentity, functionIndex, len(functions)-functionIndex)
 			break
 		}
-		functionLinkedList, functionIndex = createDAGWorkflow(functions, functionIndex, width, depth, dagIdentity)
+		workflow, functionIndex = createDAGWorkflow(functions, functionIndex, width, depth, dagIdentity)
 		dagIdentity++
 		if !test {
-			printDAG(functionLinkedList)
+			printDAG(workflow)
 		}
-		totalDAGList = append(totalDAGList, functionLinkedList)
+		totalDAGList = append(totalDAGList, workflow)
 	}
 	return totalDAGList
 }
 
-func createDAGWorkflow(functionList []*common.Function, functionID int, maxWidth int, maxDepth int, dagIdentity int) (*list.List, int) {
-	DAGList := list.New()
-	dagIdentifier := fmt.Sprintf("DAG %d,", dagIdentity)
-	var function *common.Function = functionList[functionID]
+func addDAGNode(workflow *common.Workflow, function *common.Function, depth int) *common.WorkflowNode {
+	node := workflow.AddNode(fmt.Sprintf("node-%d", len(workflow.Nodes)), function)
+	node.Depth = depth
+
+	return node
+}
+
+func createDAGWorkflow(functionList []*common.Function, functionID int, maxWidth int, maxDepth int, dagIdentity int) (*common.Workflow, int) {
+	workflow := common.NewWorkflow(fmt.Sprintf("DAG %d", dagIdentity))
+	root := addDAGNode(workflow, functionList[functionID], 0)
+	functionID += 1
 	if maxDepth == 1 {
-		DAGList.PushBack(&common.Node{Function: function, Depth: 0, DAG: dagIdentifier})
-		return DAGList, functionID + 1
+		return workflow, functionID
 	}
 	widthList := generateNodeDistribution(maxWidth, maxDepth)
-	// Implement a FIFO queue for nodes to assign functions and branches to each node.
-	nodeQueue := []*list.Element{}
 	for i := 0; i < len(widthList); i++ {
 		widthList[i] -= 1
-		DAGList.PushBack(&common.Node{Depth: -1})
 	}
-	DAGList.Front().Value = &common.Node{Function: function, Depth: 0, DAG: dagIdentifier}
-	functionID += 1
-	nodeQueue = append(nodeQueue, DAGList.Front())
+	// Implement a FIFO queue for nodes to assign functions and branches to each node.
+	nodeQueue := []*common.WorkflowNode{root}
 	for len(nodeQueue) > 0 {
-		listElement := nodeQueue[0]
+		node := nodeQueue[0]
 		nodeQueue = nodeQueue[1:]
-		node := listElement.Value.(*common.Node)
 		// Checks if the node has reached the maximum depth of the DAG (maxDepth -1)
 		if node.Depth == maxDepth-1 {
 			continue
 		}
-		child := &common.Node{Function: functionList[functionID], Depth: node.Depth + 1, DAG: dagIdentifier}
+		child := addDAGNode(workflow, functionList[functionID], node.Depth+1)
 		functionID += 1
-		listElement.Next().Value = child
-		nodeQueue = append(nodeQueue, listElement.Next())
+		workflow.AddEdge(node, child, 1)
+		nodeQueue = append(nodeQueue, child)
 		// Creating parallel branches from the node, if width of next stage > width of current stage
-		var nodeList []*list.List
 		if widthList[node.Depth+1] > 0 {
-			nodeList, nodeQueue = addBranches(nodeQueue, widthList, node, functionList, functionID, dagIdentifier)
-			functionID += len(nodeList)
-		} else {
-			nodeList = []*list.List{}
+			var additionalBranches int
+			additionalBranches, nodeQueue = addBranches(workflow, nodeQueue, widthList, node, functionList, functionID)
+			functionID += additionalBranches
 		}
-		node.Branches = nodeList
 	}
-	return DAGList, functionID
+	return workflow, functionID
 }
 
-func addBranches(nodeQueue []*list.Element, widthList []int, node *common.Node, functionList []*common.Function, functionID int, dagIdentifier string) ([]*list.List, []*list.Element) {
+func addBranches(workflow *common.Workflow, nodeQueue []*common.WorkflowNode, widthList []int, node *common.WorkflowNode, functionList []*common.Function, functionID int) (int, []*common.WorkflowNode) {
 	var additionalBranches int
-	if len(nodeQueue) < 1 || (nodeQueue[0].Value.(*common.Node).Depth > node.Depth) {
+	if len(nodeQueue) < 1 || (nodeQueue[0].Depth > node.Depth) {
 		additionalBranches = widthList[node.Depth+1]
 	} else {
 		additionalBranches = rand.Intn(widthList[node.Depth+1] + 1)
@@ -204,25 +173,14 @@ func addBranches(nodeQueue []*list.Element, widthList []int, node *common.Node,
 	for i := node.Depth + 1; i < len(widthList); i++ {
 		widthList[i] -= additionalBranches
 	}
-	nodeList := make([]*list.List, additionalBranches)
 	for i := 0; i < additionalBranches; i++ {
-		newBranch := createNewBranch(functionList, node, len(widthList), functionID, dagIdentifier)
+		// Each branch is extended with a child at every stage until the maximum depth of the DAG
+		child := addDAGNode(workflow, functionList[functionID], node.Depth+1)
 		functionID += 1
-		nodeList[i] = newBranch
-		nodeQueue = append(nodeQueue, newBranch.Front())
+		workflow.AddEdge(node, child, 1)
+		nodeQueue = append(nodeQueue, child)
 	}
-	return nodeList, nodeQueue
-}
-
-func createNewBranch(functionList []*common.Function, node *common.Node, maxDepth int, functionID int, dagIdentifier string) *list.List {
-	DAGBranch := list.New()
-	// Ensuring that each node is connected to a child until the maximum depth
-	for i := node.Depth + 1; i < maxDepth; i++ {
-		DAGBranch.PushBack(&common.Node{Depth: -1})
-	}
-	child := &common.Node{Function: functionList[functionID], Depth: node.Depth + 1, DAG: dagIdentifier}
-	DAGBranch.Front().Value = child
-	return DAGBranch
+	return additionalBranches, nodeQueue
 }
 
 func generateNodeDistribution(maxWidth int, maxDepth int) []int {
@@ -246,18 +204,6 @@ func GetMaxInvocation(functionList []*common.Function) []int {
 	return maxInvocation
 }
 
-func GetDAGShape(functionLinkedList *list.List, width *int64, depth int) (int, int) {
-	nodeElement := functionLinkedList.Front()
-	for nodeElement != nil {
-		node := nodeElement.Value.(*common.Node)
-		if len(node.Branches) != 0 {
-			for j := 0; j < len(node.Branches); j++ {
-				atomic.AddInt64(width, 1)
-				GetDAGShape(node.Branches[j], width, depth)
-			}
-		}
-		nodeElement = nodeElement.Next()
-		depth += 1
-	}
-	return int(*width), depth
+func GetDAGShape(workflow *common.Workflow) (int, int) {
+	return workflow.Shape()
 }
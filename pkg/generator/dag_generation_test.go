@@ -93,16 +93,14 @@ func TestGenerateSingleDAG(t *testing.T) {
 	for i := 0; i < len(functionList); i++ {
 		functionList[i] = functions[0]
 	}
-	dagList := GenerateDAGs(fakeConfig, functionList, true)[0]
-	branch := dagList.Front().Value.(*common.Node).Branches
-	if dagList.Len() != 2 && len(branch) != 1 {
+	dag := GenerateDAGs(fakeConfig, functionList, true)[0]
+	if len(dag.Nodes) != 3 || len(dag.Root.Successors) != 2 {
 		t.Error("Invalid DAG Generated")
 	}
 }
 
 func TestGenerateMultipleDAGs(t *testing.T) {
 	var functionList []*common.Function = make([]*common.Function, 200)
-	var initialWidth int64
 	for i := 0; i < len(functionList); i++ {
 		functionList[i] = functions[0]
 	}
@@ -113,8 +111,7 @@ func TestGenerateMultipleDAGs(t *testing.T) {
 		t.Error("Failed to create Multiple DAGs")
 	}
 	for i := 0; i < len(dagList); i++ {
-		initialWidth = 1
-		width, depth := GetDAGShape(dagList[i], &initialWidth, 0)
+		width, depth := GetDAGShape(dagList[i])
 		if width != fakeConfig.Width || depth != fakeConfig.Depth {
 			errorMsg := fmt.Sprintf("Invalid DAG Shape: Expected Width = 10, Depth = 5. Got Width = %d, Depth = %d", width, depth)
 			t.Error(errorMsg)
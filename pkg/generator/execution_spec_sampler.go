@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"math"
+	"math/rand"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+const (
+	// BucketSampler picks a percentile bucket of the trace and draws uniformly within it
+	BucketSampler = "bucket"
+	// ContinuousSampler interpolates the percentiles of the trace into a continuous inverse CDF
+	ContinuousSampler = "continuous"
+)
+
+// ExecutionSpecParameters selects how the runtime and the memory of the invocations are drawn from the percentiles of
+// the trace
+type ExecutionSpecParameters struct {
+	Sampler string
+	// Correlation of the runtime and memory quantiles through a Gaussian copula, where 1 draws both from a shared
+	// quantile and 0 draws them independently
+	Correlation float64
+}
+
+func DefaultExecutionSpecParameters() ExecutionSpecParameters {
+	return ExecutionSpecParameters{Sampler: BucketSampler}
+}
+
+// NewExecutionSpecParameters reads the sampler from the configuration, using the bucket sampler if omitted
+func NewExecutionSpecParameters(cfg *config.LoaderConfiguration) ExecutionSpecParameters {
+	result := DefaultExecutionSpecParameters()
+
+	switch cfg.ExecutionSpecSampler {
+	case "":
+	case BucketSampler, ContinuousSampler:
+		result.Sampler = cfg.ExecutionSpecSampler
+	default:
+		log.Fatalf("Unsupported execution specification sampler %s.", cfg.ExecutionSpecSampler)
+	}
+
+	if cfg.RuntimeMemoryCorrelation < -1 || cfg.RuntimeMemoryCorrelation > 1 {
+		log.Fatal("RuntimeMemoryCorrelation should be between -1 and 1.")
+	}
+	result.Correlation = cfg.RuntimeMemoryCorrelation
+
+	return result
+}
+
+// quantilePoint is a point of a piecewise inverse CDF
+type quantilePoint struct {
+	quantile float64
+	value    float64
+}
+
+func runtimeQuantilePoints(runStats *common.FunctionRuntimeStats) []quantilePoint {
+	return []quantilePoint{
+		{0, runStats.Percentile0},
+		{0.01, runStats.Percentile1},
+		{0.25, runStats.Percentile25},
+		{0.50, runStats.Percentile50},
+		{0.75, runStats.Percentile75},
+		{0.99, runStats.Percentile99},
+		{1, runStats.Percentile100},
+	}
+}
+
+// memoryQuantilePoints starts at the first percentile, as the memory trace has no minimum
+func memoryQuantilePoints(memStats *common.FunctionMemoryStats) []quantilePoint {
+	return []quantilePoint{
+		{0.01, memStats.Percentile1},
+		{0.05, memStats.Percentile5},
+		{0.25, memStats.Percentile25},
+		{0.50, memStats.Percentile50},
+		{0.75, memStats.Percentile75},
+		{0.95, memStats.Percentile95},
+		{0.99, memStats.Percentile99},
+		{1, memStats.Percentile100},
+	}
+}
+
+// interpolateQuantile evaluates the inverse CDF through the points at the given quantile. Between two positive values
+// the logarithm of the value is interpolated linearly, as runtimes and memory are closer to log-normal than to uniform
+// within a bucket. Quantiles below the first point map to its value.
+func interpolateQuantile(points []quantilePoint, quantile float64) float64 {
+	if quantile <= points[0].quantile {
+		return points[0].value
+	}
+
+	for i := 1; i < len(points); i++ {
+		if quantile > points[i].quantile {
+			continue
+		}
+
+		low, high := points[i-1], points[i]
+		if high.value <= low.value {
+			return low.value
+		}
+
+		fraction := (quantile - low.quantile) / (high.quantile - low.quantile)
+		if low.value > 0 {
+			return math.Exp(math.Log(low.value) + fraction*(math.Log(high.value)-math.Log(low.value)))
+		}
+
+		return low.value + fraction*(high.value-low.value)
+	}
+
+	return points[len(points)-1].value
+}
+
+// ContinuousExecuteSpec returns the runtime at the given quantile of the continuous inverse CDF of the trace
+func ContinuousExecuteSpec(runQtl float64, runStats *common.FunctionRuntimeStats) int {
+	return int(math.Round(interpolateQuantile(runtimeQuantilePoints(runStats), runQtl)))
+}
+
+// ContinuousMemorySpec returns the memory at the given quantile of the continuous inverse CDF of the trace
+func ContinuousMemorySpec(memQtl float64, memStats *common.FunctionMemoryStats) int {
+	return int(math.Round(interpolateQuantile(memoryQuantilePoints(memStats), memQtl)))
+}
+
+// correlatedQuantiles draws a pair of uniform quantiles whose normal scores have the given correlation
+func correlatedQuantiles(gen *rand.Rand, correlation float64) (float64, float64) {
+	first := gen.NormFloat64()
+	second := correlation*first + math.Sqrt(1-correlation*correlation)*gen.NormFloat64()
+
+	return distuv.UnitNormal.CDF(first), distuv.UnitNormal.CDF(second)
+}
@@ -0,0 +1,142 @@
+package generator
+
+import (
+	"math"
+	"math/rand"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/config"
+)
+
+const (
+	DefaultWeibullShape   = 0.5
+	DefaultParetoShape    = 1.5
+	DefaultLognormalSigma = 1.0
+	DefaultGammaShape     = 0.5
+)
+
+var (
+	DefaultMMPPRates       = [2]float64{1, 10}
+	DefaultMMPPSwitchRates = [2]float64{0.1, 0.5}
+)
+
+// IATDistributionParameters holds the parameters of the heavy-tailed and bursty IAT distributions. As the IATs of each
+// minute get normalized to the length of the minute, only the shape of the distributions matters, so all the scale
+// parameters are fixed to 1.
+type IATDistributionParameters struct {
+	WeibullShape   float64
+	ParetoShape    float64
+	LognormalSigma float64
+	GammaShape     float64
+
+	// MMPPRates Arrival rate in each of the two states of the Markov-modulated Poisson process
+	MMPPRates [2]float64
+	// MMPPSwitchRates Rate of leaving each of the two states, in the same unit of time as MMPPRates
+	MMPPSwitchRates [2]float64
+}
+
+func DefaultIATDistributionParameters() IATDistributionParameters {
+	return IATDistributionParameters{
+		WeibullShape:    DefaultWeibullShape,
+		ParetoShape:     DefaultParetoShape,
+		LognormalSigma:  DefaultLognormalSigma,
+		GammaShape:      DefaultGammaShape,
+		MMPPRates:       DefaultMMPPRates,
+		MMPPSwitchRates: DefaultMMPPSwitchRates,
+	}
+}
+
+// NewIATDistributionParameters reads the parameters from the configuration, using the defaults for the omitted ones
+func NewIATDistributionParameters(cfg *config.LoaderConfiguration) IATDistributionParameters {
+	result := DefaultIATDistributionParameters()
+
+	setPositive := func(target *float64, value float64, name string) {
+		if value < 0 {
+			log.Fatalf("%s should be positive.", name)
+		} else if value > 0 {
+			*target = value
+		}
+	}
+
+	setPositive(&result.WeibullShape, cfg.IATWeibullShape, "IATWeibullShape")
+	setPositive(&result.ParetoShape, cfg.IATParetoShape, "IATParetoShape")
+	setPositive(&result.LognormalSigma, cfg.IATLognormalSigma, "IATLognormalSigma")
+	setPositive(&result.GammaShape, cfg.IATGammaShape, "IATGammaShape")
+
+	if len(cfg.IATMMPPRates) > 0 {
+		if len(cfg.IATMMPPRates) != 2 || cfg.IATMMPPRates[0] <= 0 || cfg.IATMMPPRates[1] <= 0 {
+			log.Fatal("IATMMPPRates should contain two positive rates.")
+		}
+		copy(result.MMPPRates[:], cfg.IATMMPPRates)
+	}
+	if len(cfg.IATMMPPSwitchRates) > 0 {
+		if len(cfg.IATMMPPSwitchRates) != 2 || cfg.IATMMPPSwitchRates[0] <= 0 || cfg.IATMMPPSwitchRates[1] <= 0 {
+			log.Fatal("IATMMPPSwitchRates should contain two positive rates.")
+		}
+		copy(result.MMPPSwitchRates[:], cfg.IATMMPPSwitchRates)
+	}
+
+	return result
+}
+
+// sampleWeibull samples the Weibull distribution with the given shape and unit scale by inverting its CDF
+func sampleWeibull(rng *rand.Rand, shape float64) float64 {
+	return math.Pow(rng.ExpFloat64(), 1/shape)
+}
+
+// samplePareto samples the Pareto type I distribution with the given shape and unit minimum by inverting its CDF
+func samplePareto(rng *rand.Rand, shape float64) float64 {
+	return math.Exp(rng.ExpFloat64() / shape)
+}
+
+func sampleLognormal(rng *rand.Rand, sigma float64) float64 {
+	return math.Exp(sigma * rng.NormFloat64())
+}
+
+// sampleGamma samples the gamma distribution with the given shape and unit scale using the method of Marsaglia and
+// Tsang. Shapes below 1 are boosted to shape+1 and scaled back by U^(1/shape).
+func sampleGamma(rng *rand.Rand, shape float64) float64 {
+	if shape < 1 {
+		return sampleGamma(rng, shape+1) * math.Pow(1-rng.Float64(), 1/shape)
+	}
+
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+
+		u := 1 - rng.Float64()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
+
+// mmppProcess is a two-state Markov-modulated Poisson process. Its state is kept across minutes, so bursts can span
+// the minute boundaries.
+type mmppProcess struct {
+	state int
+}
+
+// sample returns the time until the next arrival. While waiting for the arrival, the process may switch its state.
+func (p *mmppProcess) sample(rng *rand.Rand, parameters *IATDistributionParameters) float64 {
+	result := 0.0
+
+	for {
+		toArrival := rng.ExpFloat64() / parameters.MMPPRates[p.state]
+		toSwitch := rng.ExpFloat64() / parameters.MMPPSwitchRates[p.state]
+
+		if toArrival <= toSwitch {
+			return result + toArrival
+		}
+
+		// memorylessness allows discarding the sampled arrival after the switch
+		result += toSwitch
+		p.state = 1 - p.state
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"github.com/vhive-serverless/loader/pkg/common"
 	"github.com/vhive-serverless/loader/pkg/config"
 	"math"
-	"math/rand"
 )
 
 func generateFunctionByRPS(experimentDuration int, rpsTarget float64) common.IATArray {
@@ -113,7 +112,7 @@ func CreateRPSFunctions(cfg *config.LoaderConfiguration, warmFunction common.IAT
 
 	if warmFunction != nil || warmFunctionCount != nil {
 		result = append(result, &common.Function{
-			Name: fmt.Sprintf("warm-function-%d", rand.Int()),
+			Name: fmt.Sprintf("warm-function-%d", common.NewFunctionRand(cfg.Seed, "warm-function").Int()),
 
 			InvocationStats: &common.FunctionInvocationStats{Invocations: warmFunctionCount},
 			RuntimeStats:    &common.FunctionRuntimeStats{Average: float64(cfg.RpsRuntimeMs)},
@@ -140,7 +139,7 @@ func CreateRPSFunctions(cfg *config.LoaderConfiguration, warmFunction common.IAT
 
 	for i := 0; i < len(coldFunctions); i++ {
 		result = append(result, &common.Function{
-			Name: fmt.Sprintf("cold-function-%d-%d", i, rand.Int()),
+			Name: fmt.Sprintf("cold-function-%d-%d", i, common.NewFunctionRand(cfg.Seed, fmt.Sprintf("cold-function-%d", i)).Int()),
 
 			InvocationStats: &common.FunctionInvocationStats{Invocations: coldFunctionCount[i]},
 			MemoryStats:     &common.FunctionMemoryStats{Percentile100: float64(cfg.RpsMemoryMB)},
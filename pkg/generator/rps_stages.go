@@ -0,0 +1,170 @@
+package generator
+
+import (
+	"math"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+)
+
+// rateSamplingPeriod is the period in μs during which the rate of a stage is considered to be constant
+const rateSamplingPeriod = 1000.0
+
+const defaultSpikeDurationSeconds = 10
+
+// StagedRPSLoad holds the invocations of a multi-stage RPS load profile
+type StagedRPSLoad struct {
+	// Duration of all the stages in minutes
+	Duration int
+
+	WarmFunction      common.IATArray
+	WarmFunctionCount []int
+
+	ColdFunctions     []common.IATArray
+	ColdFunctionCount [][]int
+
+	Stages []common.LoadStage
+}
+
+// stageRate returns the number of requests per second of the stage at time t μs after the beginning of the stage
+func stageRate(stage *config.RPSStage, t float64, duration float64) float64 {
+	switch stage.Shape {
+	case "", "constant":
+		return stage.StartRPS
+	case "ramp":
+		return stage.StartRPS + (stage.EndRPS-stage.StartRPS)*t/duration
+	case "step", "sweep":
+		steps := max(stage.Steps, 2)
+		level := min(int(t/duration*float64(steps)), steps-1)
+
+		return stage.StartRPS + (stage.EndRPS-stage.StartRPS)*float64(level)/float64(steps-1)
+	case "spike":
+		spikeDuration := float64(defaultSpikeDurationSeconds) * 1_000_000
+		if stage.SpikeDurationSeconds > 0 {
+			spikeDuration = float64(stage.SpikeDurationSeconds) * 1_000_000
+		}
+		spikeDuration = math.Min(spikeDuration, duration)
+
+		spikeStart := (duration - spikeDuration) / 2
+		if t >= spikeStart && t < spikeStart+spikeDuration {
+			return stage.EndRPS
+		}
+
+		return stage.StartRPS
+	default:
+		logrus.Fatalf("Unsupported shape of RPS stage - %s.", stage.Shape)
+	}
+
+	return 0
+}
+
+// generateTimestampsByRate returns the fire times in μs of invocations whose rate in requests per second is given as a
+// function of time. The first invocation is fired as soon as the rate is positive.
+func generateTimestampsByRate(duration float64, rate func(t float64) float64) []float64 {
+	var result []float64
+
+	// fraction of an invocation left until the next invocation gets fired
+	pending := 0.0
+	for t := 0.0; t < duration; t += rateSamplingPeriod {
+		perMicrosecond := rate(t) / 1_000_000
+		elapsed := 0.0
+
+		for perMicrosecond > 0 && elapsed+pending/perMicrosecond < rateSamplingPeriod {
+			elapsed += pending / perMicrosecond
+			result = append(result, t+elapsed)
+			pending = 1
+		}
+
+		pending = math.Max(pending-perMicrosecond*(rateSamplingPeriod-elapsed), 0)
+	}
+
+	return result
+}
+
+func timestampsToIAT(timestamps []float64) common.IATArray {
+	result := make(common.IATArray, len(timestamps))
+
+	previous := 0.0
+	for i, timestamp := range timestamps {
+		result[i] = timestamp - previous
+		previous = timestamp
+	}
+
+	return result
+}
+
+// GenerateStagedRPSLoad generates the warm and cold start invocations of a sequence of stages. Cold starts are assigned
+// in a round-robin fashion to a pool of functions large enough for each function to stay idle for at least
+// cooldownSeconds at the highest cold start rate of the profile.
+func GenerateStagedRPSLoad(stages []config.RPSStage, cooldownSeconds int) *StagedRPSLoad {
+	result := &StagedRPSLoad{}
+
+	var warmTimestamps, coldTimestamps []float64
+	maxColdRPS := 0.0
+
+	stageStart := 0.0
+	for i := range stages {
+		stage := &stages[i]
+		if stage.DurationMinutes <= 0 {
+			logrus.Fatalf("Duration of RPS stage %d should be at least a minute.", i)
+		}
+
+		duration := float64(stage.DurationMinutes) * 60_000_000
+		coldRatio := stage.ColdStartRatioPercentage / 100
+
+		for _, t := range generateTimestampsByRate(duration, func(t float64) float64 {
+			return stageRate(stage, t, duration) * (1 - coldRatio)
+		}) {
+			warmTimestamps = append(warmTimestamps, stageStart+t)
+		}
+
+		for _, t := range generateTimestampsByRate(duration, func(t float64) float64 {
+			coldRPS := stageRate(stage, t, duration) * coldRatio
+			maxColdRPS = math.Max(maxColdRPS, coldRPS)
+
+			return coldRPS
+		}) {
+			coldTimestamps = append(coldTimestamps, stageStart+t)
+		}
+
+		result.Stages = append(result.Stages, common.LoadStage{
+			Stage:                    i,
+			Shape:                    stage.Shape,
+			StartRPS:                 stage.StartRPS,
+			EndRPS:                   stage.EndRPS,
+			ColdStartRatioPercentage: stage.ColdStartRatioPercentage,
+			StartTime:                int64(stageStart),
+			EndTime:                  int64(stageStart + duration),
+		})
+
+		stageStart += duration
+		result.Duration += stage.DurationMinutes
+	}
+
+	if len(warmTimestamps) > 0 {
+		result.WarmFunction = timestampsToIAT(warmTimestamps)
+	}
+	result.WarmFunctionCount = countNumberOfInvocationsPerMinute(result.Duration, result.WarmFunction)
+
+	if len(coldTimestamps) > 0 {
+		poolSize := max(int(math.Ceil(maxColdRPS*float64(cooldownSeconds))), 1)
+		poolSize = min(poolSize, len(coldTimestamps))
+
+		perFunction := make([][]float64, poolSize)
+		for i, timestamp := range coldTimestamps {
+			perFunction[i%poolSize] = append(perFunction[i%poolSize], timestamp)
+		}
+
+		for _, timestamps := range perFunction {
+			iat := timestampsToIAT(timestamps)
+
+			result.ColdFunctions = append(result.ColdFunctions, iat)
+			result.ColdFunctionCount = append(result.ColdFunctionCount, countNumberOfInvocationsPerMinute(result.Duration, iat))
+		}
+
+		logrus.Warn("It is recommended that the first 10% of cold starts are discarded from the experiment results for low cold start RPS.")
+	}
+
+	return result
+}
@@ -2,6 +2,7 @@ package generator
 
 import (
 	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
 	"math"
 	"testing"
 )
@@ -404,3 +405,95 @@ func TestColdStartMatrix(t *testing.T) {
 		})
 	}
 }
+
+func TestStagedRPSLoad(t *testing.T) {
+	tests := []struct {
+		testName               string
+		stages                 []config.RPSStage
+		expectedPerMinuteCount []int
+	}{
+		{
+			testName:               "constant",
+			stages:                 []config.RPSStage{{DurationMinutes: 2, Shape: "constant", StartRPS: 1}},
+			expectedPerMinuteCount: []int{60, 60},
+		},
+		{
+			testName:               "ramp",
+			stages:                 []config.RPSStage{{DurationMinutes: 2, Shape: "ramp", StartRPS: 0, EndRPS: 4}},
+			expectedPerMinuteCount: []int{60, 180},
+		},
+		{
+			testName:               "step",
+			stages:                 []config.RPSStage{{DurationMinutes: 3, Shape: "step", StartRPS: 1, EndRPS: 3, Steps: 3}},
+			expectedPerMinuteCount: []int{60, 120, 180},
+		},
+		{
+			testName:               "spike",
+			stages:                 []config.RPSStage{{DurationMinutes: 1, Shape: "spike", StartRPS: 1, EndRPS: 11, SpikeDurationSeconds: 10}},
+			expectedPerMinuteCount: []int{160},
+		},
+		{
+			testName: "multiple_stages",
+			stages: []config.RPSStage{
+				{DurationMinutes: 1, StartRPS: 2},
+				{DurationMinutes: 1, Shape: "constant", StartRPS: 5},
+			},
+			expectedPerMinuteCount: []int{120, 300},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run("staged_rps_"+test.testName, func(t *testing.T) {
+			load := GenerateStagedRPSLoad(test.stages, 10)
+
+			if load.Duration != len(test.expectedPerMinuteCount) || len(load.Stages) != len(test.stages) {
+				t.Fatalf("Unexpected duration or number of stages - got %d and %d.", load.Duration, len(load.Stages))
+			}
+			if len(load.ColdFunctions) != 0 {
+				t.Errorf("No cold start functions expected - got %d.", len(load.ColdFunctions))
+			}
+			if test.stages[0].StartRPS > 0 && load.WarmFunction[0] != 0 {
+				t.Error("First invocation should be fired right away.")
+			}
+
+			for i, expected := range test.expectedPerMinuteCount {
+				// allow for rounding at the boundaries of the minutes
+				if math.Abs(float64(load.WarmFunctionCount[i]-expected)) > 1 {
+					t.Errorf("Unexpected count in minute %d - got: %d, expected: %d", i, load.WarmFunctionCount[i], expected)
+				}
+			}
+
+			if load.Stages[len(load.Stages)-1].EndTime != int64(load.Duration)*60_000_000 {
+				t.Error("Unexpected end of the last stage.")
+			}
+		})
+	}
+}
+
+func TestStagedRPSLoadColdStarts(t *testing.T) {
+	load := GenerateStagedRPSLoad([]config.RPSStage{
+		{DurationMinutes: 1, Shape: "constant", StartRPS: 2, ColdStartRatioPercentage: 50},
+		{DurationMinutes: 1, Shape: "ramp", StartRPS: 2, EndRPS: 4, ColdStartRatioPercentage: 50},
+	}, 10)
+
+	// highest cold start rate is 2 RPS, each function has to be idle for at least 10 seconds
+	if len(load.ColdFunctions) != 20 {
+		t.Fatalf("Unexpected number of cold start functions - got: %d, expected: 20", len(load.ColdFunctions))
+	}
+
+	total := 0
+	for i, iat := range load.ColdFunctions {
+		for j := 1; j < len(iat); j++ {
+			if iat[j] < 10_000_000-rateSamplingPeriod {
+				t.Errorf("Function %d is invoked before the end of the cooldown - IAT %f.", i, iat[j])
+			}
+		}
+
+		total += len(iat)
+	}
+
+	// 60 cold starts in the first minute and 90 in the second one
+	if math.Abs(float64(total-150)) > 1 {
+		t.Errorf("Unexpected number of cold starts - got: %d, expected: 150", total)
+	}
+}
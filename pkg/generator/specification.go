@@ -25,6 +25,7 @@
 package generator
 
 import (
+	"math"
 	"math/rand"
 
 	log "github.com/sirupsen/logrus"
@@ -32,17 +33,61 @@ import (
 )
 
 type SpecificationGenerator struct {
+	seed     int64
 	iatRand  *rand.Rand
 	specRand *rand.Rand
+
+	iatParameters  IATDistributionParameters
+	mmpp           *mmppProcess
+	execParameters ExecutionSpecParameters
 }
 
 func NewSpecificationGenerator(seed int64) *SpecificationGenerator {
 	return &SpecificationGenerator{
+		seed:     seed,
 		iatRand:  rand.New(rand.NewSource(seed)),
 		specRand: rand.New(rand.NewSource(seed)),
+
+		iatParameters:  DefaultIATDistributionParameters(),
+		execParameters: DefaultExecutionSpecParameters(),
 	}
 }
 
+// FunctionIdentity identifies a function by its hashes in the trace, or by its name for functions that are not part of
+// a trace
+func FunctionIdentity(function *common.Function) string {
+	if function.InvocationStats != nil && function.InvocationStats.HashFunction != "" {
+		stats := function.InvocationStats
+		return stats.HashOwner + "/" + stats.HashApp + "/" + stats.HashFunction
+	}
+
+	return function.Name
+}
+
+// ForFunction returns a generator with the same parameters and random streams of its own for the function, derived
+// from the seed and the identity of the function. The specification of a function then does not depend on the other
+// functions of the trace nor on their order, so any subset of the trace yields the same specifications.
+func (s *SpecificationGenerator) ForFunction(function *common.Function) *SpecificationGenerator {
+	identity := FunctionIdentity(function)
+
+	return &SpecificationGenerator{
+		seed:     s.seed,
+		iatRand:  common.NewFunctionRand(s.seed, identity),
+		specRand: common.NewFunctionRand(s.seed, identity),
+
+		iatParameters:  s.iatParameters,
+		execParameters: s.execParameters,
+	}
+}
+
+func (s *SpecificationGenerator) SetIATDistributionParameters(parameters IATDistributionParameters) {
+	s.iatParameters = parameters
+}
+
+func (s *SpecificationGenerator) SetExecutionSpecParameters(parameters ExecutionSpecParameters) {
+	s.execParameters = parameters
+}
+
 //////////////////////////////////////////////////
 // IAT GENERATION
 //////////////////////////////////////////////////
@@ -73,6 +118,16 @@ func (s *SpecificationGenerator) generateIATPerGranularity(numberOfInvocations i
 			}
 
 			iat = equalDistance
+		case common.Weibull:
+			iat = sampleWeibull(s.iatRand, s.iatParameters.WeibullShape)
+		case common.Pareto:
+			iat = samplePareto(s.iatRand, s.iatParameters.ParetoShape)
+		case common.Lognormal:
+			iat = sampleLognormal(s.iatRand, s.iatParameters.LognormalSigma)
+		case common.Gamma:
+			iat = sampleGamma(s.iatRand, s.iatParameters.GammaShape)
+		case common.MMPP:
+			iat = s.mmpp.sample(s.iatRand, &s.iatParameters)
 		default:
 			log.Fatal("Unsupported IAT distribution.")
 		}
@@ -90,9 +145,10 @@ func (s *SpecificationGenerator) generateIATPerGranularity(numberOfInvocations i
 		totalDuration = 1
 	}
 
-	if iatDistribution == common.Uniform || iatDistribution == common.Exponential {
+	if iatDistribution != common.Equidistant {
 		// Uniform: 		we need to scale IAT from [0, 1) to [0, 60 seconds)
 		// Exponential: 	we need to scale IAT from [0, +MaxFloat64) to [0, 60 seconds)
+		// Others:		same as exponential, keeping only the shape of the distribution
 		for i := 0; i < len(iatResult); i++ {
 			// how much does the IAT contributes to the total IAT sum
 			iatResult[i] = iatResult[i] / totalDuration
@@ -139,6 +195,19 @@ func getBlankTimeUnit(granularity common.TraceGranularity) float64 {
 	}
 }
 
+// resetArrivalProcess prepares the state of the distributions that depend on the previous arrivals before the
+// generation of the IATs of a function
+func (s *SpecificationGenerator) resetArrivalProcess(iatDistribution common.IatDistribution) {
+	if iatDistribution == common.MMPP {
+		// each function starts in a state drawn from the stationary distribution of the process
+		s.mmpp = &mmppProcess{}
+		switchRates := s.iatParameters.MMPPSwitchRates
+		if s.iatRand.Float64() < switchRates[0]/(switchRates[0]+switchRates[1]) {
+			s.mmpp.state = 1
+		}
+	}
+}
+
 // GenerateIAT generates IAT according to the given distribution. Number of minutes is the length of invocationsPerMinute array
 func (s *SpecificationGenerator) generateIAT(invocationsPerMinute []int, iatDistribution common.IatDistribution,
 	shiftIAT bool, granularity common.TraceGranularity) (common.IATArray, []int, common.ProbabilisticDuration) {
@@ -147,6 +216,8 @@ func (s *SpecificationGenerator) generateIAT(invocationsPerMinute []int, iatDist
 	var perMinuteCount []int
 	var nonScaledDuration []float64
 
+	s.resetArrivalProcess(iatDistribution)
+
 	numberOfMinutes := len(invocationsPerMinute)
 	for i := 0; i < numberOfMinutes; i++ {
 		minuteIAT, duration := s.generateIATPerGranularity(invocationsPerMinute[i], iatDistribution, shiftIAT, granularity)
@@ -184,6 +255,25 @@ func (s *SpecificationGenerator) GenerateInvocationData(function *common.Functio
 	}
 }
 
+// ScaleSpecificationTime compresses (timeScale > 1) or stretches (timeScale < 1) the timeline of the specification
+// while keeping its relative arrival structure. The per-minute counts keep referring to the minutes of the trace.
+func ScaleSpecificationTime(spec *common.FunctionSpecification, timeScale float64, scaleRuntimes bool) {
+	for i := range spec.IAT {
+		spec.IAT[i] /= timeScale
+	}
+
+	if scaleRuntimes {
+		for i := range spec.RuntimeSpecification {
+			spec.RuntimeSpecification[i].Runtime = scaleRuntime(spec.RuntimeSpecification[i].Runtime, timeScale)
+		}
+	}
+}
+
+// scaleRuntime scales the runtime along with the timeline, without going below the minimal execution time
+func scaleRuntime(runtime int, timeScale float64) int {
+	return common.MaxOf(common.MinExecTimeMilli, int(math.Round(float64(runtime)/timeScale)))
+}
+
 //////////////////////////////////////////////////
 // RUNTIME AND MEMORY GENERATION
 //////////////////////////////////////////////////
@@ -205,6 +295,10 @@ func randIntBetween(gen *rand.Rand, min, max float64) int {
 
 // Should be called only when specRand is locked with its mutex
 func (s *SpecificationGenerator) determineExecutionSpecSeedQuantiles() (float64, float64) {
+	if s.execParameters.Correlation != 0 {
+		return correlatedQuantiles(s.specRand, s.execParameters.Correlation)
+	}
+
 	//* Generate uniform quantiles in [0, 1).
 	runQtl := s.specRand.Float64()
 	memQtl := s.specRand.Float64()
@@ -265,8 +359,15 @@ func (s *SpecificationGenerator) generateExecutionSpecs(function *common.Functio
 	}
 
 	runQtl, memQtl := s.determineExecutionSpecSeedQuantiles()
-	runtime := common.MinOf(common.MaxExecTimeMilli, common.MaxOf(common.MinExecTimeMilli, GenerateExecuteSpec(s.specRand, runQtl, runStats)))
-	memory := common.MinOf(common.MaxMemQuotaMib, common.MaxOf(common.MinMemQuotaMib, GenerateMemorySpec(s.specRand, memQtl, memStats)))
+
+	var runtime, memory int
+	if s.execParameters.Sampler == ContinuousSampler {
+		runtime, memory = ContinuousExecuteSpec(runQtl, runStats), ContinuousMemorySpec(memQtl, memStats)
+	} else {
+		runtime, memory = GenerateExecuteSpec(s.specRand, runQtl, runStats), GenerateMemorySpec(s.specRand, memQtl, memStats)
+	}
+	runtime = common.MinOf(common.MaxExecTimeMilli, common.MaxOf(common.MinExecTimeMilli, runtime))
+	memory = common.MinOf(common.MaxMemQuotaMib, common.MaxOf(common.MinMemQuotaMib, memory))
 
 	return common.RuntimeSpecification{
 		Runtime: runtime,
@@ -0,0 +1,89 @@
+package generator
+
+import (
+	"github.com/vhive-serverless/loader/pkg/common"
+)
+
+// SpecificationStream generates the specification of a function one minute at a time, so that only the invocations of
+// the current minute are kept in memory. The stream yields the same invocations as GenerateInvocationData of a
+// generator created with the same seed.
+type SpecificationStream struct {
+	function  *common.Function
+	generator *SpecificationGenerator
+
+	iatDistribution common.IatDistribution
+	shiftIAT        bool
+	granularity     common.TraceGranularity
+	timeScale       float64
+	scaleRuntimes   bool
+
+	minute int
+	// pending is the IAT of the next invocation, which may get extended by the blank beginning of the next minute
+	pending float64
+
+	iat                  []float64
+	runtimeSpecification []common.RuntimeSpecification
+	index                int
+}
+
+func NewSpecificationStream(function *common.Function, generator *SpecificationGenerator, iatDistribution common.IatDistribution,
+	shiftIAT bool, granularity common.TraceGranularity, timeScale float64, scaleRuntimes bool) *SpecificationStream {
+
+	generator.resetArrivalProcess(iatDistribution)
+
+	return &SpecificationStream{
+		function:  function,
+		generator: generator,
+
+		iatDistribution: iatDistribution,
+		shiftIAT:        shiftIAT,
+		granularity:     granularity,
+		timeScale:       timeScale,
+		scaleRuntimes:   scaleRuntimes,
+	}
+}
+
+// nextMinute generates the invocations of the next minute of the trace that has at least one invocation
+func (s *SpecificationStream) nextMinute() bool {
+	invocationsPerMinute := s.function.InvocationStats.Invocations
+
+	for s.index >= len(s.iat) {
+		if s.minute >= len(invocationsPerMinute) {
+			return false
+		}
+
+		minuteIAT, _ := s.generator.generateIATPerGranularity(invocationsPerMinute[s.minute], s.iatDistribution, s.shiftIAT, s.granularity)
+		s.minute++
+
+		// the last IAT of a minute is only known once the beginning of the next minute has been generated
+		s.pending += minuteIAT[0]
+		s.iat, s.runtimeSpecification, s.index = s.iat[:0], s.runtimeSpecification[:0], 0
+		for _, iat := range minuteIAT[1:] {
+			s.iat = append(s.iat, s.pending)
+			s.runtimeSpecification = append(s.runtimeSpecification, s.generator.generateExecutionSpecs(s.function))
+			s.pending = iat
+		}
+	}
+
+	return true
+}
+
+// Next returns the IAT in μs and the runtime specification of the next invocation, and false once the invocations of
+// all the minutes of the trace have been returned
+func (s *SpecificationStream) Next() (float64, *common.RuntimeSpecification, bool) {
+	if !s.nextMinute() {
+		return 0, nil, false
+	}
+
+	iat, runtimeSpecification := s.iat[s.index], s.runtimeSpecification[s.index]
+	s.index++
+
+	if s.timeScale > 0 {
+		iat /= s.timeScale
+		if s.scaleRuntimes {
+			runtimeSpecification.Runtime = scaleRuntime(runtimeSpecification.Runtime, s.timeScale)
+		}
+	}
+
+	return iat, &runtimeSpecification, true
+}
@@ -29,12 +29,15 @@ import (
 	"math"
 	"os"
 	"os/exec"
+	"sort"
 	"strconv"
 	"sync"
 	"testing"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/vhive-serverless/loader/pkg/common"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
 )
 
 var testFunction = common.Function{
@@ -836,3 +839,407 @@ func TestGenerateExecutionSpecifications(t *testing.T) {
 		})
 	}
 }
+
+func TestScaleSpecificationTime(t *testing.T) {
+	spec := &common.FunctionSpecification{
+		IAT:                  common.IATArray{0, 60_000_000, 30_000_000},
+		PerMinuteCount:       []int{1, 2},
+		RuntimeSpecification: common.RuntimeSpecificationArray{{Runtime: 100, Memory: 128}, {Runtime: 50, Memory: 128}, {Runtime: 1, Memory: 128}},
+	}
+
+	ScaleSpecificationTime(spec, 10, true)
+
+	expectedIAT := common.IATArray{0, 6_000_000, 3_000_000}
+	for i := range expectedIAT {
+		if spec.IAT[i] != expectedIAT[i] {
+			t.Errorf("Unexpected IAT %d - got %f, expected %f.", i, spec.IAT[i], expectedIAT[i])
+		}
+	}
+
+	if spec.PerMinuteCount[0] != 1 || spec.PerMinuteCount[1] != 2 {
+		t.Error("Per-minute counts should keep referring to the minutes of the trace.")
+	}
+
+	// runtimes are not scaled below MinExecTimeMilli
+	expectedRuntimes := []int{10, 5, common.MinExecTimeMilli}
+	for i, expected := range expectedRuntimes {
+		if spec.RuntimeSpecification[i].Runtime != expected || spec.RuntimeSpecification[i].Memory != 128 {
+			t.Errorf("Unexpected runtime specification %d - %v.", i, spec.RuntimeSpecification[i])
+		}
+	}
+}
+
+// rawMinuteIAT reverts the normalization of the IATs of a single minute to the length of the minute
+func rawMinuteIAT(iat []float64, nonScaledDuration float64) []float64 {
+	var result []float64
+	for _, v := range iat[1:] {
+		result = append(result, v*nonScaledDuration/60_000_000)
+	}
+
+	return result
+}
+
+func TestHeavyTailedIATDistributions(t *testing.T) {
+	tests := []struct {
+		testName        string
+		iatDistribution common.IatDistribution
+		parameters      IATDistributionParameters
+		cdf             func(float64) float64
+	}{
+		{
+			testName:        "weibull",
+			iatDistribution: common.Weibull,
+			parameters:      IATDistributionParameters{WeibullShape: 0.7},
+			cdf:             distuv.Weibull{K: 0.7, Lambda: 1}.CDF,
+		},
+		{
+			testName:        "pareto",
+			iatDistribution: common.Pareto,
+			parameters:      IATDistributionParameters{ParetoShape: 1.2},
+			cdf:             distuv.Pareto{Xm: 1, Alpha: 1.2}.CDF,
+		},
+		{
+			testName:        "lognormal",
+			iatDistribution: common.Lognormal,
+			parameters:      IATDistributionParameters{LognormalSigma: 1.5},
+			cdf:             distuv.LogNormal{Mu: 0, Sigma: 1.5}.CDF,
+		},
+		{
+			testName:        "gamma_shape_below_one",
+			iatDistribution: common.Gamma,
+			parameters:      IATDistributionParameters{GammaShape: 0.4},
+			cdf:             distuv.Gamma{Alpha: 0.4, Beta: 1}.CDF,
+		},
+		{
+			testName:        "gamma_shape_above_one",
+			iatDistribution: common.Gamma,
+			parameters:      IATDistributionParameters{GammaShape: 3},
+			cdf:             distuv.Gamma{Alpha: 3, Beta: 1}.CDF,
+		},
+	}
+
+	const invocations = 10_000
+	// critical value of the Kolmogorov-Smirnov test for a significance level of 0.001
+	criticalValue := 1.95 / math.Sqrt(invocations)
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			sg := NewSpecificationGenerator(123456789)
+			sg.SetIATDistributionParameters(test.parameters)
+
+			iat, nonScaledDuration := sg.generateIATPerGranularity(invocations, test.iatDistribution, false, common.MinuteGranularity)
+			if len(iat) != invocations+1 {
+				t.Fatalf("Wrong number of IATs in the minute, got: %d, expected: %d.", len(iat), invocations+1)
+			}
+			if math.Abs(stat.Mean(iat, nil)*float64(len(iat))-60_000_000) > 1 {
+				t.Error("IATs have not been normalized to the length of the minute.")
+			}
+
+			if d := kolmogorovSmirnovStatistic(rawMinuteIAT(iat, nonScaledDuration), test.cdf); d > criticalValue {
+				t.Errorf("The provided sample does not satisfy the given distribution - D = %f, critical value = %f.", d, criticalValue)
+			}
+		})
+	}
+}
+
+func TestHeavyTailedIATPerMinuteCount(t *testing.T) {
+	invocations := []int{5, 0, 100, 1, 30}
+
+	for _, distribution := range []common.IatDistribution{common.Weibull, common.Pareto, common.Lognormal, common.Gamma, common.MMPP} {
+		for _, shiftIAT := range []bool{false, true} {
+			sg := NewSpecificationGenerator(42)
+
+			testFunction.InvocationStats = &common.FunctionInvocationStats{Invocations: invocations}
+			spec := sg.GenerateInvocationData(&testFunction, distribution, shiftIAT, common.MinuteGranularity)
+
+			for i := range invocations {
+				if spec.PerMinuteCount[i] != invocations[i] {
+					t.Errorf("Distribution %d, shift %v: wrong per-minute count in minute %d - got: %d, expected: %d.",
+						distribution, shiftIAT, i, spec.PerMinuteCount[i], invocations[i])
+				}
+			}
+
+			sum := 0.0
+			for _, iat := range spec.IAT {
+				sum += iat
+			}
+			if sum > float64(len(invocations))*60_000_000 {
+				t.Errorf("Distribution %d, shift %v: invocations spill over the end of the trace.", distribution, shiftIAT)
+			}
+		}
+	}
+}
+
+func TestMMPPIATDistribution(t *testing.T) {
+	parameters := DefaultIATDistributionParameters()
+	parameters.MMPPRates = [2]float64{1, 20}
+	parameters.MMPPSwitchRates = [2]float64{0.05, 0.2}
+
+	sg := NewSpecificationGenerator(123456789)
+	sg.SetIATDistributionParameters(parameters)
+
+	testFunction.InvocationStats = &common.FunctionInvocationStats{Invocations: []int{20_000, 20_000, 20_000, 20_000, 20_000}}
+	spec := sg.GenerateInvocationData(&testFunction, common.MMPP, false, common.MinuteGranularity)
+
+	var sample []float64
+	beginIndex := 0
+	for i, count := range spec.PerMinuteCount {
+		sample = append(sample, rawMinuteIAT(spec.IAT[beginIndex:beginIndex+count], spec.RawDuration[i])...)
+		beginIndex += count
+	}
+
+	// the mean IAT is the inverse of the arrival rate averaged over the stationary distribution of the states
+	stationaryHigh := parameters.MMPPSwitchRates[0] / (parameters.MMPPSwitchRates[0] + parameters.MMPPSwitchRates[1])
+	expectedMean := 1 / ((1-stationaryHigh)*parameters.MMPPRates[0] + stationaryHigh*parameters.MMPPRates[1])
+
+	mean, std := stat.MeanStdDev(sample, nil)
+	if math.Abs(mean-expectedMean)/expectedMean > 0.1 {
+		t.Errorf("Unexpected mean IAT - got: %f, expected: %f.", mean, expectedMean)
+	}
+
+	// a Poisson process has a coefficient of variation of 1, while a modulated one is burstier
+	if cv := std / mean; cv < 1.5 {
+		t.Errorf("MMPP arrivals are not bursty - coefficient of variation: %f.", cv)
+	}
+	if d := kolmogorovSmirnovStatistic(sample, distuv.Exponential{Rate: 1 / mean}.CDF); d < 1.95/math.Sqrt(float64(len(sample))) {
+		t.Error("MMPP arrivals cannot be distinguished from a Poisson process.")
+	}
+}
+
+func TestSpecificationStream(t *testing.T) {
+	tests := []struct {
+		testName        string
+		iatDistribution common.IatDistribution
+		shiftIAT        bool
+		timeScale       float64
+	}{
+		{testName: "exponential", iatDistribution: common.Exponential},
+		{testName: "exponential_shift", iatDistribution: common.Exponential, shiftIAT: true},
+		{testName: "equidistant_scaled", iatDistribution: common.Equidistant, timeScale: 4},
+		{testName: "equidistant_compressed", iatDistribution: common.Equidistant, timeScale: 10_000},
+		{testName: "mmpp_shift", iatDistribution: common.MMPP, shiftIAT: true},
+	}
+
+	invocations := []int{0, 3, 0, 0, 10, 1, 0}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			testFunction.InvocationStats = &common.FunctionInvocationStats{Invocations: invocations}
+
+			spec := NewSpecificationGenerator(42).GenerateInvocationData(&testFunction, test.iatDistribution, test.shiftIAT, common.MinuteGranularity)
+			if test.timeScale > 0 {
+				ScaleSpecificationTime(spec, test.timeScale, true)
+			}
+
+			stream := NewSpecificationStream(&testFunction, NewSpecificationGenerator(42), test.iatDistribution, test.shiftIAT,
+				common.MinuteGranularity, test.timeScale, true)
+
+			for i := 0; ; i++ {
+				iat, runtimeSpecification, ok := stream.Next()
+				if !ok {
+					if i != len(spec.IAT) {
+						t.Errorf("Stream has ended after %d invocations, expected %d.", i, len(spec.IAT))
+					}
+					break
+				}
+
+				if i >= len(spec.IAT) {
+					t.Fatalf("Stream yields more than %d invocations.", len(spec.IAT))
+				}
+				if runtimeSpecification.Runtime < common.MinExecTimeMilli {
+					t.Errorf("Invocation %d has a runtime of %d ms.", i, runtimeSpecification.Runtime)
+				}
+				if math.Abs(iat-spec.IAT[i]) > 1e-3 || *runtimeSpecification != spec.RuntimeSpecification[i] {
+					t.Errorf("Invocation %d differs - got: %f, %+v, expected: %f, %+v.", i, iat, *runtimeSpecification,
+						spec.IAT[i], spec.RuntimeSpecification[i])
+				}
+			}
+		})
+	}
+}
+
+func TestContinuousExecutionSpecSampler(t *testing.T) {
+	function := &common.Function{
+		Name: "continuous",
+		RuntimeStats: &common.FunctionRuntimeStats{
+			Count:         1000,
+			Percentile0:   5,
+			Percentile1:   10,
+			Percentile25:  40,
+			Percentile50:  100,
+			Percentile75:  300,
+			Percentile99:  2000,
+			Percentile100: 5000,
+		},
+		MemoryStats: &common.FunctionMemoryStats{
+			Count:         1000,
+			Percentile1:   130,
+			Percentile5:   140,
+			Percentile25:  170,
+			Percentile50:  200,
+			Percentile75:  260,
+			Percentile95:  400,
+			Percentile99:  600,
+			Percentile100: 1000,
+		},
+	}
+
+	sg := NewSpecificationGenerator(42)
+	sg.SetExecutionSpecParameters(ExecutionSpecParameters{Sampler: ContinuousSampler})
+
+	const samples = 100_000
+	var runtimes, memory []float64
+	for i := 0; i < samples; i++ {
+		spec := sg.generateExecutionSpecs(function)
+
+		runtimes = append(runtimes, float64(spec.Runtime))
+		memory = append(memory, float64(spec.Memory))
+	}
+	sort.Float64s(runtimes)
+	sort.Float64s(memory)
+
+	for _, point := range runtimeQuantilePoints(function.RuntimeStats)[1:6] {
+		got := stat.Quantile(point.quantile, stat.Empirical, runtimes, nil)
+		if math.Abs(got-point.value)/point.value > 0.05 {
+			t.Errorf("Runtime percentile %.2f - got: %.1f, expected: %.1f.", point.quantile, got, point.value)
+		}
+	}
+	for _, point := range memoryQuantilePoints(function.MemoryStats)[1:7] {
+		got := stat.Quantile(point.quantile, stat.Empirical, memory, nil)
+		if math.Abs(got-point.value)/point.value > 0.05 {
+			t.Errorf("Memory percentile %.2f - got: %.1f, expected: %.1f.", point.quantile, got, point.value)
+		}
+	}
+
+	// a continuous inverse CDF has no point masses at the percentiles of the trace, unlike the bucket sampler
+	atMedian := 0
+	for _, runtime := range runtimes {
+		if runtime == function.RuntimeStats.Percentile50 {
+			atMedian++
+		}
+	}
+	if atMedian > samples/100 {
+		t.Errorf("Too many runtimes at the median - got: %d.", atMedian)
+	}
+
+	if got := ContinuousExecuteSpec(0.5, function.RuntimeStats); got != 100 {
+		t.Errorf("Unexpected median runtime - got: %d, expected: 100.", got)
+	}
+	if got := ContinuousExecuteSpec(0.375, function.RuntimeStats); got != 63 {
+		t.Errorf("Runtime should be interpolated log-linearly - got: %d, expected: 63.", got)
+	}
+}
+
+func TestCorrelatedExecutionSpecQuantiles(t *testing.T) {
+	tests := []struct {
+		correlation float64
+		// Spearman correlation of the quantiles of a Gaussian copula
+		expected float64
+	}{
+		{correlation: 1, expected: 1},
+		{correlation: 0.5, expected: 6 / math.Pi * math.Asin(0.25)},
+		{correlation: -0.8, expected: 6 / math.Pi * math.Asin(-0.4)},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("correlation_%.1f", test.correlation), func(t *testing.T) {
+			sg := NewSpecificationGenerator(42)
+			sg.SetExecutionSpecParameters(ExecutionSpecParameters{Sampler: ContinuousSampler, Correlation: test.correlation})
+
+			var runQuantiles, memQuantiles []float64
+			for i := 0; i < 50_000; i++ {
+				runQtl, memQtl := sg.determineExecutionSpecSeedQuantiles()
+
+				runQuantiles = append(runQuantiles, runQtl)
+				memQuantiles = append(memQuantiles, memQtl)
+			}
+
+			// the quantiles are uniform, so their Pearson correlation is the Spearman correlation of the draws
+			if got := stat.Correlation(runQuantiles, memQuantiles, nil); math.Abs(got-test.expected) > 0.02 {
+				t.Errorf("Unexpected correlation - got: %.3f, expected: %.3f.", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestValidateSpecification(t *testing.T) {
+	tests := []struct {
+		testName        string
+		iatDistribution common.IatDistribution
+		shiftIAT        bool
+		timeScale       float64
+	}{
+		{testName: "exponential", iatDistribution: common.Exponential},
+		{testName: "exponential_shift", iatDistribution: common.Exponential, shiftIAT: true},
+		{testName: "uniform_scaled", iatDistribution: common.Uniform, timeScale: 10},
+		{testName: "gamma", iatDistribution: common.Gamma},
+		{testName: "equidistant", iatDistribution: common.Equidistant},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			function := testFunction
+			function.InvocationStats = &common.FunctionInvocationStats{Invocations: []int{500, 0, 1500, 20}}
+			// runtimes below MinExecTimeMilli would be clamped
+			runtimeStats := *testFunction.RuntimeStats
+			runtimeStats.Percentile0 = common.MinExecTimeMilli
+			function.RuntimeStats = &runtimeStats
+
+			sg := NewSpecificationGenerator(42)
+			function.Specification = sg.GenerateInvocationData(&function, test.iatDistribution, test.shiftIAT, common.MinuteGranularity)
+			if test.timeScale > 0 {
+				ScaleSpecificationTime(function.Specification, test.timeScale, false)
+			}
+
+			validation := sg.ValidateFunction(&function, test.iatDistribution, common.MinuteGranularity, test.timeScale, DefaultValidationThresholds())
+			if !validation.Passed {
+				t.Errorf("Generated specification should pass the validation - %v.", validation.Failures)
+			}
+			if test.iatDistribution != common.Equidistant && validation.KSSamples != 499+1499+19 {
+				t.Errorf("Unexpected number of IATs in the KS test - got: %d.", validation.KSSamples)
+			}
+			if len(validation.RuntimeQuantiles) != 5 || len(validation.MemoryQuantiles) != 6 {
+				t.Error("Runtime and memory percentiles have not been compared.")
+			}
+
+			// another distribution than the one the IATs have been drawn from
+			if test.iatDistribution == common.Exponential && !test.shiftIAT {
+				if sg.ValidateFunction(&function, common.Uniform, common.MinuteGranularity, 0, DefaultValidationThresholds()).Passed {
+					t.Error("Exponential IATs should fail the validation against the uniform distribution.")
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSpecificationFailures(t *testing.T) {
+	function := testFunction
+	function.InvocationStats = &common.FunctionInvocationStats{Invocations: []int{200, 200}}
+	function.RuntimeStats = &common.FunctionRuntimeStats{
+		Count:         100,
+		Percentile0:   10_000,
+		Percentile1:   20_000,
+		Percentile25:  40_000,
+		Percentile50:  70_000,
+		Percentile75:  90_000,
+		Percentile99:  100_000,
+		Percentile100: 120_000,
+	}
+
+	sg := NewSpecificationGenerator(42)
+	function.Specification = sg.GenerateInvocationData(&function, common.Exponential, false, common.MinuteGranularity)
+	function.Specification.PerMinuteCount = []int{199, 201}
+
+	report := sg.ValidateSpecification([]*common.Function{&function}, common.Exponential, common.MinuteGranularity, 0, DefaultValidationThresholds())
+	if report.Passed || report.FailedFunctions != 1 {
+		t.Fatal("Specification should fail the validation.")
+	}
+
+	validation := report.Functions[0]
+	if validation.CountError != 2 {
+		t.Errorf("Unexpected count error - got: %d, expected: 2.", validation.CountError)
+	}
+	if validation.ClampedRuntimes < 150 || validation.ClampedMemory != 0 {
+		t.Errorf("Unexpected number of clamped specifications - got: %d runtimes, %d memory.", validation.ClampedRuntimes, validation.ClampedMemory)
+	}
+}
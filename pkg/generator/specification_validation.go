@@ -0,0 +1,255 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// ValidationThresholds bound the deviation of the generated specifications from the trace that a validation accepts
+type ValidationThresholds struct {
+	// MaxCountError is the largest number of invocations by which the per-minute counts may differ from the trace
+	MaxCountError int `json:"maxCountError"`
+	// KSCoefficient sets the critical value of the Kolmogorov-Smirnov test to KSCoefficient / sqrt(n), where 1.95
+	// corresponds to a significance level of 0.001
+	KSCoefficient float64 `json:"ksCoefficient"`
+	// MaxQuantileError is the largest relative error of a runtime or memory percentile, with a slack of one unit
+	MaxQuantileError float64 `json:"maxQuantileError"`
+	// MaxClampedFraction is the largest fraction of the invocations whose runtime or memory may have been clamped
+	MaxClampedFraction float64 `json:"maxClampedFraction"`
+	// MinSamples is the number of IATs or invocations a function needs for the statistical checks to apply
+	MinSamples int `json:"minSamples"`
+}
+
+func DefaultValidationThresholds() ValidationThresholds {
+	return ValidationThresholds{
+		MaxCountError:      0,
+		KSCoefficient:      1.95,
+		MaxQuantileError:   0.1,
+		MaxClampedFraction: 0.01,
+		MinSamples:         100,
+	}
+}
+
+type QuantileComparison struct {
+	Quantile  float64 `json:"quantile"`
+	Trace     float64 `json:"trace"`
+	Generated float64 `json:"generated"`
+}
+
+// FunctionValidation compares the specification of a function with its trace
+type FunctionValidation struct {
+	Function     string `json:"function"`
+	HashFunction string `json:"hashFunction"`
+	Invocations  int    `json:"invocations"`
+
+	// CountError is the sum of the absolute differences between the generated and the trace per-minute counts
+	CountError int `json:"countError"`
+
+	// KSStatistic of the IATs against the configured distribution, omitted for distributions without a closed-form CDF
+	KSStatistic     float64 `json:"ksStatistic,omitempty"`
+	KSCriticalValue float64 `json:"ksCriticalValue,omitempty"`
+	KSSamples       int     `json:"ksSamples,omitempty"`
+
+	RuntimeQuantiles []QuantileComparison `json:"runtimeQuantiles,omitempty"`
+	MemoryQuantiles  []QuantileComparison `json:"memoryQuantiles,omitempty"`
+
+	// ClampedRuntimes and ClampedMemory count the invocations cut to the bounds of MinExecTimeMilli, MaxExecTimeMilli,
+	// MinMemQuotaMib and MaxMemQuotaMib
+	ClampedRuntimes int `json:"clampedRuntimes"`
+	ClampedMemory   int `json:"clampedMemory"`
+
+	Failures []string `json:"failures,omitempty"`
+	Passed   bool     `json:"passed"`
+}
+
+// SpecificationReport summarizes the fidelity of the specifications of all the functions
+type SpecificationReport struct {
+	Thresholds ValidationThresholds  `json:"thresholds"`
+	Functions  []*FunctionValidation `json:"functions"`
+
+	FailedFunctions int  `json:"failedFunctions"`
+	Passed          bool `json:"passed"`
+}
+
+// iatCDF returns the CDF of the IATs drawn by the generator before their normalization, or nil if the distribution
+// has no closed-form CDF or is deterministic
+func (s *SpecificationGenerator) iatCDF(iatDistribution common.IatDistribution) func(float64) float64 {
+	switch iatDistribution {
+	case common.Exponential:
+		return distuv.Exponential{Rate: 1}.CDF
+	case common.Uniform:
+		return distuv.Uniform{Min: 0, Max: 1}.CDF
+	case common.Weibull:
+		return distuv.Weibull{K: s.iatParameters.WeibullShape, Lambda: 1}.CDF
+	case common.Pareto:
+		return distuv.Pareto{Xm: 1, Alpha: s.iatParameters.ParetoShape}.CDF
+	case common.Lognormal:
+		return distuv.LogNormal{Mu: 0, Sigma: s.iatParameters.LognormalSigma}.CDF
+	case common.Gamma:
+		return distuv.Gamma{Alpha: s.iatParameters.GammaShape, Beta: 1}.CDF
+	default:
+		return nil
+	}
+}
+
+// rawIATs reverts the normalization of the IATs within each minute of the specification. The first IAT of each minute
+// spans the previous minute, so it is left out.
+func rawIATs(spec *common.FunctionSpecification, granularity common.TraceGranularity, timeScale float64) []float64 {
+	if timeScale <= 0 {
+		timeScale = 1
+	}
+
+	var result []float64
+	begin := 0
+	for minute, count := range spec.PerMinuteCount {
+		if minute < len(spec.RawDuration) && begin+count <= len(spec.IAT) {
+			for _, iat := range spec.IAT[min(begin+1, begin+count) : begin+count] {
+				result = append(result, iat*timeScale*spec.RawDuration[minute]/getBlankTimeUnit(granularity))
+			}
+		}
+
+		begin += count
+	}
+
+	return result
+}
+
+func kolmogorovSmirnovStatistic(sample []float64, cdf func(float64) float64) float64 {
+	sorted := append([]float64{}, sample...)
+	sort.Float64s(sorted)
+
+	n := float64(len(sorted))
+	result := 0.0
+	for i, x := range sorted {
+		f := cdf(x)
+		result = math.Max(result, math.Max(f-float64(i)/n, float64(i+1)/n-f))
+	}
+
+	return result
+}
+
+func compareQuantiles(points []quantilePoint, sample []float64) []QuantileComparison {
+	sorted := append([]float64{}, sample...)
+	sort.Float64s(sorted)
+
+	var result []QuantileComparison
+	for _, point := range points {
+		result = append(result, QuantileComparison{
+			Quantile:  point.quantile,
+			Trace:     point.value,
+			Generated: stat.Quantile(point.quantile, stat.Empirical, sorted, nil),
+		})
+	}
+
+	return result
+}
+
+func (v *FunctionValidation) checkQuantiles(name string, comparisons []QuantileComparison, thresholds ValidationThresholds) {
+	for _, comparison := range comparisons {
+		if math.Abs(comparison.Generated-comparison.Trace) > math.Max(thresholds.MaxQuantileError*comparison.Trace, 1) {
+			v.Failures = append(v.Failures, fmt.Sprintf("%s percentile %g is %g instead of %g", name,
+				comparison.Quantile*100, comparison.Generated, comparison.Trace))
+		}
+	}
+}
+
+// ValidateFunction compares the generated specification of a function with the per-minute counts and the runtime and
+// memory percentiles of its trace and the IATs with the distribution they have been drawn from
+func (s *SpecificationGenerator) ValidateFunction(function *common.Function, iatDistribution common.IatDistribution,
+	granularity common.TraceGranularity, timeScale float64, thresholds ValidationThresholds) *FunctionValidation {
+
+	spec := function.Specification
+	result := &FunctionValidation{
+		Function:    function.Name,
+		Invocations: len(spec.IAT),
+	}
+	if function.InvocationStats != nil {
+		result.HashFunction = function.InvocationStats.HashFunction
+
+		for minute, expected := range function.InvocationStats.Invocations {
+			generated := 0
+			if minute < len(spec.PerMinuteCount) {
+				generated = spec.PerMinuteCount[minute]
+			}
+
+			result.CountError += common.MaxOf(generated-expected, expected-generated)
+		}
+	}
+	if result.CountError > thresholds.MaxCountError {
+		result.Failures = append(result.Failures, fmt.Sprintf("per-minute counts differ by %d invocations", result.CountError))
+	}
+
+	if cdf := s.iatCDF(iatDistribution); cdf != nil && !function.ReplaySpecification {
+		sample := rawIATs(spec, granularity, timeScale)
+		if len(sample) >= thresholds.MinSamples {
+			result.KSSamples = len(sample)
+			result.KSStatistic = kolmogorovSmirnovStatistic(sample, cdf)
+			result.KSCriticalValue = thresholds.KSCoefficient / math.Sqrt(float64(len(sample)))
+
+			if result.KSStatistic > result.KSCriticalValue {
+				result.Failures = append(result.Failures, fmt.Sprintf("IATs do not follow the distribution - D = %f, critical value = %f",
+					result.KSStatistic, result.KSCriticalValue))
+			}
+		}
+	}
+
+	var runtimes, memory []float64
+	for _, runtimeSpecification := range spec.RuntimeSpecification {
+		runtimes = append(runtimes, float64(runtimeSpecification.Runtime))
+		memory = append(memory, float64(runtimeSpecification.Memory))
+
+		if function.RuntimeStats != nil && ((runtimeSpecification.Runtime == common.MinExecTimeMilli && function.RuntimeStats.Percentile0 < common.MinExecTimeMilli) ||
+			(runtimeSpecification.Runtime == common.MaxExecTimeMilli && function.RuntimeStats.Percentile100 > common.MaxExecTimeMilli)) {
+			result.ClampedRuntimes++
+		}
+		if function.MemoryStats != nil && ((runtimeSpecification.Memory == common.MinMemQuotaMib && function.MemoryStats.Percentile1 < common.MinMemQuotaMib) ||
+			(runtimeSpecification.Memory == common.MaxMemQuotaMib && function.MemoryStats.Percentile100 > common.MaxMemQuotaMib)) {
+			result.ClampedMemory++
+		}
+	}
+
+	if len(runtimes) >= thresholds.MinSamples {
+		if function.RuntimeStats != nil {
+			result.RuntimeQuantiles = compareQuantiles(runtimeQuantilePoints(function.RuntimeStats)[1:6], runtimes)
+			result.checkQuantiles("runtime", result.RuntimeQuantiles, thresholds)
+		}
+		if function.MemoryStats != nil {
+			result.MemoryQuantiles = compareQuantiles(memoryQuantilePoints(function.MemoryStats)[1:7], memory)
+			result.checkQuantiles("memory", result.MemoryQuantiles, thresholds)
+		}
+	}
+
+	if clamped := max(result.ClampedRuntimes, result.ClampedMemory); len(runtimes) > 0 &&
+		float64(clamped)/float64(len(runtimes)) > thresholds.MaxClampedFraction {
+
+		result.Failures = append(result.Failures, fmt.Sprintf("%d runtimes and %d memory specifications have been clamped",
+			result.ClampedRuntimes, result.ClampedMemory))
+	}
+
+	result.Passed = len(result.Failures) == 0
+
+	return result
+}
+
+// ValidateSpecification validates the generated specifications of all the functions
+func (s *SpecificationGenerator) ValidateSpecification(functions []*common.Function, iatDistribution common.IatDistribution,
+	granularity common.TraceGranularity, timeScale float64, thresholds ValidationThresholds) *SpecificationReport {
+
+	report := &SpecificationReport{Thresholds: thresholds, Passed: true}
+	for _, function := range functions {
+		validation := s.ValidateFunction(function, iatDistribution, granularity, timeScale, thresholds)
+		if !validation.Passed {
+			report.FailedFunctions++
+			report.Passed = false
+		}
+
+		report.Functions = append(report.Functions, validation)
+	}
+
+	return report
+}
@@ -81,6 +81,77 @@ type ExecutionRecord struct {
 	UserCodeExecutionMs int64  `csv:"userCodeExecutionMs"`
 
 	TimeToGetResponseMs int64 `csv:"timeToGetResponseMs"`
+
+	// ScheduledTime Time at which the loader intended to fire the invocation
+	ScheduledTime int64 `csv:"scheduledTime"`
+	// DispatchLag Time in microseconds between ScheduledTime and the moment the invocation was picked up by an
+	// invocation worker
+	DispatchLag int64 `csv:"dispatchLag"`
+
+	// Effects of the invoker middlewares, with the delays in microseconds
+	Retries            int    `csv:"retries"`
+	RateLimitDelay     int64  `csv:"rateLimitDelay"`
+	InFlightLimitDelay int64  `csv:"inFlightLimitDelay"`
+	InjectedFault      string `csv:"injectedFault"`
+
+	// Client-side breakdown of HTTP invocations in microseconds, while GRPCConnectionEstablishTime holds the time to
+	// obtain a connection, which includes the DNS lookup, the TCP connect and the TLS handshake of a new connection
+	DNSLookupTime    int64 `csv:"dnsLookup"`
+	TCPConnectTime   int64 `csv:"tcpConnect"`
+	TLSHandshakeTime int64 `csv:"tlsHandshake"`
+	// RequestWriteTime Time from obtaining a connection until the request has been written
+	RequestWriteTime int64 `csv:"requestWrite"`
+	// TimeToFirstByte Time from writing the request until the first byte of the response, i.e., the network round trip
+	// and the time spent in the platform and the function
+	TimeToFirstByte int64 `csv:"timeToFirstByte"`
+	// BodyReadTime Time from receiving the response headers until the whole body has been read
+	BodyReadTime     int64 `csv:"bodyRead"`
+	ConnectionReused bool  `csv:"connectionReused"`
+
+	// RequestBytes and ResponseBytes are the sizes of the gRPC messages or of the HTTP bodies
+	RequestBytes  int `csv:"requestBytes"`
+	ResponseBytes int `csv:"responseBytes"`
+	// PayloadValidation is valid or invalid if the invocation has carried a payload whose checksums the function has
+	// reported, and empty otherwise
+	PayloadValidation string `csv:"payloadValidation"`
+}
+
+// WorkflowRecord describes one invocation of a DAG workflow, from the start of its root until the completion of its
+// last node
+type WorkflowRecord struct {
+	Phase         int    `csv:"phase"`
+	Workflow      string `csv:"workflow"`
+	InvocationID  string `csv:"invocationID"`
+	ScheduledTime int64  `csv:"scheduledTime"`
+
+	// StartTime and EndTime in microseconds
+	StartTime int64 `csv:"startTime"`
+	EndTime   int64 `csv:"endTime"`
+	Latency   int64 `csv:"latency"`
+
+	// NodeCount Number of nodes that have been invoked out of the WorkflowSize nodes of the workflow
+	NodeCount    int `csv:"nodeCount"`
+	WorkflowSize int `csv:"workflowSize"`
+	// FailedNode Name of the first node whose invocation has failed, empty if none has failed
+	FailedNode string `csv:"failedNode"`
+	// CriticalPath Functions on the path that determined the completion of the workflow, separated by '>'
+	CriticalPath string `csv:"criticalPath"`
+}
+
+// FunctionManifestRecord maps a deployed function to the function of the trace it has been generated from and to its
+// resource settings
+type FunctionManifestRecord struct {
+	Function     string `csv:"function"`
+	HashOwner    string `csv:"hashOwner"`
+	HashApp      string `csv:"hashApp"`
+	HashFunction string `csv:"hashFunction"`
+	Trigger      string `csv:"trigger"`
+
+	CPURequestsMilli    int `csv:"cpuRequestsMilli"`
+	CPULimitsMilli      int `csv:"cpuLimitsMilli"`
+	MemoryRequestsMiB   int `csv:"memoryRequestsMiB"`
+	ColdStartBusyLoopMs int `csv:"coldStartBusyLoopMs"`
+	InitialScale        int `csv:"initialScale"`
 }
 
 type DeploymentScale struct {
@@ -0,0 +1,281 @@
+package simulator
+
+import (
+	"math"
+	"slices"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+)
+
+const (
+	DefaultKeepAliveSeconds = 600
+
+	// hybridBinSeconds is the width of the bins of the idle time histogram of the hybrid policy
+	hybridBinSeconds = 60
+	// hybridRangeMinutes is the range of the histogram and the keep-alive of the hybrid policy when the histogram is
+	// not representative yet
+	hybridRangeMinutes = 240
+	hybridHeadQuantile = 0.05
+	hybridTailQuantile = 0.99
+	hybridMargin       = 0.1
+	hybridMinSamples   = 10
+)
+
+// Invocation is one invocation of a function, with its arrival and runtime in seconds
+type Invocation struct {
+	Arrival float64
+	Runtime float64
+}
+
+// Result holds the outcome of the simulation of a policy for one function
+type Result struct {
+	Policy       string `csv:"policy"`
+	Function     string `csv:"function"`
+	HashFunction string `csv:"hashFunction"`
+
+	Invocations int `csv:"invocations"`
+	ColdStarts  int `csv:"coldStarts"`
+	// MaxInstances is the largest number of instances of the function alive at the same time
+	MaxInstances int `csv:"maxInstances"`
+	// InstanceSeconds is the time the instances of the function have been loaded in memory
+	InstanceSeconds float64 `csv:"instanceSeconds"`
+	// MemorySeconds is InstanceSeconds weighted by the memory of the function in MiB
+	MemorySeconds float64 `csv:"memorySeconds"`
+}
+
+// Policy decides when instances of a function are created and removed
+type Policy interface {
+	Name() string
+	// Simulate replays the invocations sorted by arrival until the end of the experiment in seconds
+	Simulate(invocations []Invocation, end float64) *Result
+}
+
+// NewPolicies creates the policies selected by the configuration, all of them if none is selected
+func NewPolicies(cfg *config.LoaderConfiguration) []Policy {
+	keepAlive := float64(DefaultKeepAliveSeconds)
+	if cfg.SimulatorKeepAliveSeconds > 0 {
+		keepAlive = float64(cfg.SimulatorKeepAliveSeconds)
+	}
+
+	names := cfg.SimulatorPolicies
+	if len(names) == 0 {
+		names = []string{"fixed", "hybrid", "knative"}
+	}
+
+	var result []Policy
+	for _, name := range names {
+		switch name {
+		case "fixed":
+			result = append(result, &FixedKeepAlive{KeepAlive: keepAlive})
+		case "hybrid":
+			result = append(result, &HybridHistogram{})
+		case "knative":
+			result = append(result, NewKnativeAutoscaler(cfg.SimulatorTargetConcurrency, cfg.SimulatorStableWindowSeconds))
+		default:
+			log.Fatalf("Unsupported keep-alive policy %s.", name)
+		}
+	}
+
+	return result
+}
+
+// Invocations returns the invocations of a specification in seconds. The IATs are relative to the previous invocation,
+// the first one to the beginning of the experiment.
+func Invocations(spec *common.FunctionSpecification) []Invocation {
+	result := make([]Invocation, 0, len(spec.IAT))
+
+	arrival := 0.0
+	for i, iat := range spec.IAT {
+		arrival += iat / 1_000_000
+
+		runtime := 0.0
+		if i < len(spec.RuntimeSpecification) {
+			runtime = float64(spec.RuntimeSpecification[i].Runtime) / 1000
+		}
+
+		result = append(result, Invocation{Arrival: arrival, Runtime: runtime})
+	}
+
+	return result
+}
+
+// SimulateFunction replays the specification of the function under each of the policies. The memory of the instances is
+// the largest memory of the invocations of the function.
+func SimulateFunction(function *common.Function, policies []Policy, end float64) []*Result {
+	invocations := Invocations(function.Specification)
+
+	memory := 0
+	for _, runtimeSpecification := range function.Specification.RuntimeSpecification {
+		memory = max(memory, runtimeSpecification.Memory)
+	}
+	if len(invocations) > 0 {
+		last := invocations[len(invocations)-1]
+		end = math.Max(end, last.Arrival+last.Runtime)
+	}
+
+	var result []*Result
+	for _, policy := range policies {
+		policyResult := policy.Simulate(invocations, end)
+
+		policyResult.Function = function.Name
+		if function.InvocationStats != nil {
+			policyResult.HashFunction = function.InvocationStats.HashFunction
+		}
+		policyResult.MemorySeconds = policyResult.InstanceSeconds * float64(memory)
+
+		result = append(result, policyResult)
+	}
+
+	return result
+}
+
+// instance of a function that serves a single invocation at a time and stays loaded for the keep-alive window of the
+// policy after each invocation
+type instance struct {
+	busyUntil  float64
+	loadedFrom float64
+	expiresAt  float64
+	// loaded time accumulated up to loadedFrom
+	seconds float64
+}
+
+// keepAliveWindow returns how long an instance stays unloaded after an invocation before getting pre-warmed and how
+// long it then stays loaded
+type keepAliveWindow func(invocation Invocation) (float64, float64)
+
+// simulateKeepAlive replays the invocations on a pool of instances, reusing the loaded instance that has been used
+// most recently, and creating an instance, i.e., a cold start, if none is available
+func simulateKeepAlive(invocations []Invocation, end float64, window keepAliveWindow) *Result {
+	result := &Result{Invocations: len(invocations)}
+
+	var pool []*instance
+	retire := func(i *instance) {
+		result.InstanceSeconds += i.seconds + math.Max(i.expiresAt-i.loadedFrom, 0)
+	}
+
+	for _, invocation := range invocations {
+		t := invocation.Arrival
+
+		var selected *instance
+		pool = slices.DeleteFunc(pool, func(i *instance) bool {
+			if i.busyUntil <= t && t > i.expiresAt {
+				retire(i)
+				return true
+			}
+
+			return false
+		})
+		for _, i := range pool {
+			if i.busyUntil <= t && i.loadedFrom <= t && (selected == nil || i.busyUntil > selected.busyUntil) {
+				selected = i
+			}
+		}
+
+		if selected == nil {
+			result.ColdStarts++
+
+			selected = &instance{}
+			pool = append(pool, selected)
+			result.MaxInstances = max(result.MaxInstances, len(pool))
+		} else {
+			selected.seconds += t - selected.loadedFrom
+		}
+
+		selected.busyUntil = t + invocation.Runtime
+		selected.seconds += invocation.Runtime
+
+		unloaded, loaded := window(invocation)
+		selected.loadedFrom = selected.busyUntil + unloaded
+		selected.expiresAt = selected.loadedFrom + loaded
+	}
+
+	// instances are accounted for until the end of the experiment
+	for _, i := range pool {
+		i.expiresAt = math.Max(math.Min(i.expiresAt, end), i.loadedFrom)
+		retire(i)
+	}
+
+	return result
+}
+
+// FixedKeepAlive keeps every instance loaded for a fixed time after each invocation, as most commercial platforms do
+type FixedKeepAlive struct {
+	KeepAlive float64
+}
+
+func (p *FixedKeepAlive) Name() string {
+	return "fixed"
+}
+
+func (p *FixedKeepAlive) Simulate(invocations []Invocation, end float64) *Result {
+	result := simulateKeepAlive(invocations, end, func(Invocation) (float64, float64) {
+		return 0, p.KeepAlive
+	})
+	result.Policy = p.Name()
+
+	return result
+}
+
+// HybridHistogram is the hybrid histogram policy of Serverless in the Wild (ATC'20). It keeps a histogram of the idle
+// times of the function, i.e., the times between the end of an invocation and the next arrival. Once the histogram
+// is representative, an instance is unloaded after an invocation until the head of the histogram, lowered by a margin,
+// and is kept loaded until its tail, raised by a margin. Until then, instances are kept loaded for the whole range of
+// the histogram.
+type HybridHistogram struct{}
+
+func (p *HybridHistogram) Name() string {
+	return "hybrid"
+}
+
+func (p *HybridHistogram) Simulate(invocations []Invocation, end float64) *Result {
+	histogram := make([]int, hybridRangeMinutes)
+	samples, outOfRange := 0, 0
+	lastEnd := math.Inf(-1)
+
+	// the idle times are observed in the order of the arrivals, before the window of the invocation is decided
+	observe := func(invocation Invocation) {
+		if !math.IsInf(lastEnd, -1) {
+			bin := int(math.Max(invocation.Arrival-lastEnd, 0) / hybridBinSeconds)
+			if bin < len(histogram) {
+				histogram[bin]++
+				samples++
+			} else {
+				outOfRange++
+			}
+		}
+
+		lastEnd = math.Max(lastEnd, invocation.Arrival+invocation.Runtime)
+	}
+
+	percentileBin := func(quantile float64) int {
+		target := int(math.Ceil(quantile * float64(samples)))
+
+		count := 0
+		for bin, binCount := range histogram {
+			count += binCount
+			if count >= max(target, 1) {
+				return bin
+			}
+		}
+
+		return len(histogram) - 1
+	}
+
+	result := simulateKeepAlive(invocations, end, func(invocation Invocation) (float64, float64) {
+		observe(invocation)
+
+		if samples < hybridMinSamples || outOfRange > samples {
+			return 0, hybridRangeMinutes * hybridBinSeconds
+		}
+
+		unloaded := float64(percentileBin(hybridHeadQuantile)*hybridBinSeconds) * (1 - hybridMargin)
+		loaded := float64((percentileBin(hybridTailQuantile)+1)*hybridBinSeconds)*(1+hybridMargin) - unloaded
+
+		return unloaded, loaded
+	})
+	result.Policy = p.Name()
+
+	return result
+}
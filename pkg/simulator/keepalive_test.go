@@ -0,0 +1,185 @@
+package simulator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+)
+
+func TestInvocations(t *testing.T) {
+	spec := &common.FunctionSpecification{
+		IAT: []float64{1_000_000, 2_500_000, 500_000},
+		RuntimeSpecification: []common.RuntimeSpecification{
+			{Runtime: 100, Memory: 128},
+			{Runtime: 2000, Memory: 256},
+			{Runtime: 10, Memory: 128},
+		},
+	}
+
+	expected := []Invocation{{1, 0.1}, {3.5, 2}, {4, 0.01}}
+	for i, invocation := range Invocations(spec) {
+		if math.Abs(invocation.Arrival-expected[i].Arrival) > 1e-9 || math.Abs(invocation.Runtime-expected[i].Runtime) > 1e-9 {
+			t.Errorf("Invocation %d is %v instead of %v.", i, invocation, expected[i])
+		}
+	}
+}
+
+func TestFixedKeepAlive(t *testing.T) {
+	tests := []struct {
+		name            string
+		invocations     []Invocation
+		coldStarts      int
+		maxInstances    int
+		instanceSeconds float64
+	}{
+		{
+			name:            "expired_instance",
+			invocations:     []Invocation{{0, 1}, {5, 1}, {100, 1}},
+			coldStarts:      2,
+			maxInstances:    1,
+			instanceSeconds: 16 + 11,
+		},
+		{
+			name:            "simultaneous_invocations",
+			invocations:     []Invocation{{0, 1}, {0.5, 1}, {1.5, 1}},
+			coldStarts:      2,
+			maxInstances:    2,
+			instanceSeconds: 11 + 12,
+		},
+		{
+			name:            "no_invocations",
+			coldStarts:      0,
+			maxInstances:    0,
+			instanceSeconds: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := (&FixedKeepAlive{KeepAlive: 10}).Simulate(test.invocations, 200)
+
+			if result.Policy != "fixed" || result.Invocations != len(test.invocations) {
+				t.Errorf("Unexpected policy %s or number of invocations %d.", result.Policy, result.Invocations)
+			}
+			if result.ColdStarts != test.coldStarts {
+				t.Errorf("Got %d cold starts, expected %d.", result.ColdStarts, test.coldStarts)
+			}
+			if result.MaxInstances != test.maxInstances {
+				t.Errorf("Got %d instances, expected %d.", result.MaxInstances, test.maxInstances)
+			}
+			if math.Abs(result.InstanceSeconds-test.instanceSeconds) > 1e-9 {
+				t.Errorf("Got %f instance-seconds, expected %f.", result.InstanceSeconds, test.instanceSeconds)
+			}
+		})
+	}
+}
+
+func TestFixedKeepAliveEndOfExperiment(t *testing.T) {
+	result := (&FixedKeepAlive{KeepAlive: 600}).Simulate([]Invocation{{0, 1}}, 60)
+
+	if result.InstanceSeconds != 60 {
+		t.Errorf("Instance should be accounted for until the end of the experiment, got %f instance-seconds.", result.InstanceSeconds)
+	}
+}
+
+func TestHybridHistogram(t *testing.T) {
+	// an invocation every 10 minutes for 10 hours
+	var invocations []Invocation
+	for i := 0; i < 60; i++ {
+		invocations = append(invocations, Invocation{Arrival: float64(i * 600), Runtime: 1})
+	}
+	end := float64(60 * 600)
+
+	hybrid := (&HybridHistogram{}).Simulate(invocations, end)
+	fallback := (&FixedKeepAlive{KeepAlive: hybridRangeMinutes * hybridBinSeconds}).Simulate(invocations, end)
+	fixed := (&FixedKeepAlive{KeepAlive: 300}).Simulate(invocations, end)
+
+	if hybrid.Policy != "hybrid" {
+		t.Errorf("Unexpected policy %s.", hybrid.Policy)
+	}
+	if hybrid.ColdStarts > 2 {
+		t.Errorf("Hybrid policy should pre-warm periodic invocations, got %d cold starts.", hybrid.ColdStarts)
+	}
+	if fixed.ColdStarts != len(invocations) {
+		t.Errorf("Keep-alive shorter than the period should always cause cold starts, got %d.", fixed.ColdStarts)
+	}
+	if hybrid.InstanceSeconds >= fallback.InstanceSeconds/2 {
+		t.Errorf("Hybrid policy should unload idle instances, got %f instance-seconds compared to %f.",
+			hybrid.InstanceSeconds, fallback.InstanceSeconds)
+	}
+}
+
+func TestKnativeAutoscaler(t *testing.T) {
+	t.Run("burst", func(t *testing.T) {
+		var invocations []Invocation
+		for i := 0; i < 10; i++ {
+			invocations = append(invocations, Invocation{Arrival: 1, Runtime: 5})
+		}
+
+		result := NewKnativeAutoscaler(1, 60).Simulate(invocations, 600)
+		if result.ColdStarts != 10 || result.MaxInstances != 10 {
+			t.Errorf("Burst should create a pod per invocation, got %d cold starts and %d pods.", result.ColdStarts, result.MaxInstances)
+		}
+	})
+
+	t.Run("steady_traffic", func(t *testing.T) {
+		// one invocation per second taking half a second
+		var invocations []Invocation
+		for i := 0; i < 300; i++ {
+			invocations = append(invocations, Invocation{Arrival: float64(i) + 0.25, Runtime: 0.5})
+		}
+
+		result := NewKnativeAutoscaler(1, 60).Simulate(invocations, 300)
+		if result.ColdStarts != 1 || result.MaxInstances != 1 {
+			t.Errorf("Steady traffic should be served by a single pod, got %d cold starts and %d pods.", result.ColdStarts, result.MaxInstances)
+		}
+	})
+
+	t.Run("scale_to_zero", func(t *testing.T) {
+		invocations := []Invocation{{0, 1}, {1000, 1}}
+
+		result := NewKnativeAutoscaler(1, 60).Simulate(invocations, 2000)
+		if result.ColdStarts != 2 {
+			t.Errorf("Idle pods should be scaled to zero, got %d cold starts.", result.ColdStarts)
+		}
+		// each pod lives for the stable window and the grace period, rounded to the ticks
+		if result.InstanceSeconds < 2*(60+knativeScaleToZeroGraceSeconds) || result.InstanceSeconds > 2*(60+knativeScaleToZeroGraceSeconds+2*knativeTickSeconds) {
+			t.Errorf("Unexpected %f instance-seconds.", result.InstanceSeconds)
+		}
+	})
+}
+
+func TestSimulateFunction(t *testing.T) {
+	function := &common.Function{
+		Name:            "test-function",
+		InvocationStats: &common.FunctionInvocationStats{HashFunction: "hash"},
+		Specification: &common.FunctionSpecification{
+			IAT: []float64{0, 5_000_000},
+			RuntimeSpecification: []common.RuntimeSpecification{
+				{Runtime: 1000, Memory: 128},
+				{Runtime: 1000, Memory: 256},
+			},
+		},
+	}
+
+	policies := NewPolicies(&config.LoaderConfiguration{SimulatorKeepAliveSeconds: 10})
+	if len(policies) != 3 {
+		t.Fatalf("Expected all the policies by default, got %d.", len(policies))
+	}
+
+	results := SimulateFunction(function, policies, 200)
+	for _, result := range results {
+		if result.Function != "test-function" || result.HashFunction != "hash" {
+			t.Errorf("Unexpected function %s (%s).", result.Function, result.HashFunction)
+		}
+		if result.MemorySeconds != 256*result.InstanceSeconds {
+			t.Errorf("Memory-seconds of policy %s should be weighted by the largest memory.", result.Policy)
+		}
+	}
+
+	if results[0].Policy != "fixed" || results[0].ColdStarts != 1 || results[0].InstanceSeconds != 16 {
+		t.Errorf("Unexpected result of the fixed policy %+v.", *results[0])
+	}
+}
@@ -0,0 +1,208 @@
+package simulator
+
+import (
+	"math"
+	"slices"
+)
+
+const (
+	DefaultTargetConcurrency   = 1
+	DefaultStableWindowSeconds = 60
+
+	knativeTickSeconds             = 2
+	knativePanicWindowPercentage   = 10
+	knativePanicThreshold          = 2
+	knativeScaleToZeroGraceSeconds = 30
+)
+
+// KnativeAutoscaler models the Knative Pod Autoscaler. Every tick, the average concurrency over the stable window
+// determines the number of pods, each serving up to TargetConcurrency invocations at a time. When the concurrency over
+// the panic window reaches twice the capacity of the pods, the autoscaler panics and scales up without scaling down
+// until the concurrency has stayed below the threshold for a stable window. An invocation that finds no pod with a free
+// slot causes a cold start, and the pods are scaled to zero once there has been no traffic for the stable window and
+// the grace period.
+type KnativeAutoscaler struct {
+	TargetConcurrency int
+	StableWindow      float64
+}
+
+func NewKnativeAutoscaler(targetConcurrency int, stableWindowSeconds int) *KnativeAutoscaler {
+	result := &KnativeAutoscaler{
+		TargetConcurrency: DefaultTargetConcurrency,
+		StableWindow:      DefaultStableWindowSeconds,
+	}
+
+	if targetConcurrency > 0 {
+		result.TargetConcurrency = targetConcurrency
+	}
+	if stableWindowSeconds > 0 {
+		result.StableWindow = float64(stableWindowSeconds)
+	}
+
+	return result
+}
+
+func (p *KnativeAutoscaler) Name() string {
+	return "knative"
+}
+
+type pod struct {
+	created float64
+	// ends of the invocations the pod is serving
+	inFlight []float64
+}
+
+func (p *pod) release(t float64) {
+	p.inFlight = slices.DeleteFunc(p.inFlight, func(end float64) bool {
+		return end <= t
+	})
+}
+
+type knativeSimulation struct {
+	policy *KnativeAutoscaler
+	result *Result
+
+	pods []*pod
+	// invocations that have not ended before the beginning of the current tick
+	recent []Invocation
+	// average concurrency of each tick, the last one being the most recent
+	concurrency []float64
+
+	panicUntil float64
+	zeroSince  float64
+}
+
+// tick runs the autoscaler at the end of the tick at time t
+func (s *knativeSimulation) tick(t float64) {
+	begin := t - knativeTickSeconds
+
+	// the concurrency is averaged over the tick, like the queue proxy does, so that short invocations are not missed
+	busy := 0.0
+	for _, invocation := range s.recent {
+		busy += math.Max(math.Min(invocation.Arrival+invocation.Runtime, t)-math.Max(invocation.Arrival, begin), 0)
+	}
+	s.recent = slices.DeleteFunc(s.recent, func(invocation Invocation) bool {
+		return invocation.Arrival+invocation.Runtime <= t
+	})
+
+	stableTicks := max(int(s.policy.StableWindow/knativeTickSeconds), 1)
+	panicTicks := max(stableTicks*knativePanicWindowPercentage/100, 1)
+
+	s.concurrency = append(s.concurrency, busy/knativeTickSeconds)
+	if len(s.concurrency) > stableTicks {
+		s.concurrency = s.concurrency[1:]
+	}
+
+	average := func(ticks int) float64 {
+		window := s.concurrency[max(len(s.concurrency)-ticks, 0):]
+
+		sum := 0.0
+		for _, c := range window {
+			sum += c
+		}
+
+		return sum / float64(ticks)
+	}
+
+	target := float64(s.policy.TargetConcurrency)
+	stableDesired := int(math.Ceil(average(stableTicks) / target))
+	panicDesired := int(math.Ceil(average(panicTicks) / target))
+
+	for _, p := range s.pods {
+		p.release(t)
+	}
+
+	if len(s.pods) > 0 && float64(panicDesired) >= knativePanicThreshold*float64(len(s.pods)) {
+		s.panicUntil = t + s.policy.StableWindow
+	}
+
+	desired := stableDesired
+	if t < s.panicUntil {
+		// no scaling down in panic mode
+		desired = max(panicDesired, stableDesired, len(s.pods))
+	}
+
+	if desired > 0 {
+		s.zeroSince = math.Inf(1)
+	} else if math.IsInf(s.zeroSince, 1) {
+		s.zeroSince = t
+	}
+
+	if desired == 0 && t-s.zeroSince < knativeScaleToZeroGraceSeconds {
+		desired = min(len(s.pods), 1)
+	}
+
+	for len(s.pods) < desired {
+		s.addPod(t)
+	}
+
+	// only idle pods are removed, the ones created last first
+	for i := len(s.pods) - 1; i >= 0 && len(s.pods) > desired; i-- {
+		if len(s.pods[i].inFlight) == 0 {
+			s.removePod(i, t)
+		}
+	}
+}
+
+func (s *knativeSimulation) addPod(t float64) *pod {
+	result := &pod{created: t}
+
+	s.pods = append(s.pods, result)
+	s.result.MaxInstances = max(s.result.MaxInstances, len(s.pods))
+
+	return result
+}
+
+func (s *knativeSimulation) removePod(index int, t float64) {
+	s.result.InstanceSeconds += t - s.pods[index].created
+	s.pods = slices.Delete(s.pods, index, index+1)
+}
+
+func (s *knativeSimulation) invoke(invocation Invocation) {
+	var selected *pod
+	for _, p := range s.pods {
+		p.release(invocation.Arrival)
+
+		// the least loaded pod serves the invocation
+		if len(p.inFlight) < s.policy.TargetConcurrency && (selected == nil || len(p.inFlight) < len(selected.inFlight)) {
+			selected = p
+		}
+	}
+
+	if selected == nil {
+		s.result.ColdStarts++
+		selected = s.addPod(invocation.Arrival)
+	}
+
+	selected.inFlight = append(selected.inFlight, invocation.Arrival+invocation.Runtime)
+	s.recent = append(s.recent, invocation)
+	s.zeroSince = math.Inf(1)
+}
+
+func (p *KnativeAutoscaler) Simulate(invocations []Invocation, end float64) *Result {
+	s := &knativeSimulation{
+		policy:    p,
+		result:    &Result{Policy: p.Name(), Invocations: len(invocations)},
+		zeroSince: math.Inf(1),
+	}
+
+	nextTick := float64(knativeTickSeconds)
+	for _, invocation := range invocations {
+		for ; nextTick <= invocation.Arrival; nextTick += knativeTickSeconds {
+			s.tick(nextTick)
+		}
+
+		s.invoke(invocation)
+	}
+
+	for ; nextTick <= end && (len(s.pods) > 0 || len(s.recent) > 0); nextTick += knativeTickSeconds {
+		s.tick(nextTick)
+	}
+
+	// pods are accounted for until the end of the experiment
+	for len(s.pods) > 0 {
+		s.removePod(len(s.pods)-1, math.Max(math.Min(nextTick, end), s.pods[len(s.pods)-1].created))
+	}
+
+	return s.result
+}
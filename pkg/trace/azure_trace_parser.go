@@ -26,33 +26,43 @@ package trace
 
 import (
 	"encoding/csv"
-	"fmt"
 	"github.com/gocarina/gocsv"
 	"github.com/vhive-serverless/loader/pkg/common"
 	"github.com/vhive-serverless/loader/pkg/generator"
 	"io"
-	"math/rand"
 	"os"
 	"strconv"
 	"strings"
-	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
 type AzureTraceParser struct {
 	DirectoryPath string
-
-	duration              int
-	functionNameGenerator *rand.Rand
+	// Names of the files of the trace within DirectoryPath
+	InvocationsFile string
+	DurationsFile   string
+	MemoryFile      string
+	// Seed from which the names and the cold start busy loops of the functions are derived
+	Seed int64
+
+	duration int
 }
 
+const (
+	DefaultAzureInvocationsFile = "invocations.csv"
+	DefaultAzureDurationsFile   = "durations.csv"
+	DefaultAzureMemoryFile      = "memory.csv"
+)
+
 func NewAzureParser(directoryPath string, totalDuration int) *AzureTraceParser {
 	return &AzureTraceParser{
-		DirectoryPath: directoryPath,
+		DirectoryPath:   directoryPath,
+		InvocationsFile: DefaultAzureInvocationsFile,
+		DurationsFile:   DefaultAzureDurationsFile,
+		MemoryFile:      DefaultAzureMemoryFile,
 
-		duration:              totalDuration,
-		functionNameGenerator: rand.New(rand.NewSource(time.Now().UnixNano())),
+		duration: totalDuration,
 	}
 }
 
@@ -92,31 +102,30 @@ func (p *AzureTraceParser) extractFunctions(invocations *[]common.FunctionInvoca
 	runtimeByHashFunction := createRuntimeMap(runtime)
 	memoryByHashFunction := createMemoryMap(memory)
 
-	gen := rand.New(rand.NewSource(time.Now().UnixNano()))
-
 	for i := 0; i < len(*invocations); i++ {
 		invocationStats := (*invocations)[i]
 
 		function := &common.Function{
-			Name: fmt.Sprintf("%s-%d-%d", common.FunctionNamePrefix, i, p.functionNameGenerator.Uint64()),
-
 			InvocationStats: &invocationStats,
 			RuntimeStats:    runtimeByHashFunction[invocationStats.HashFunction],
 			MemoryStats:     memoryByHashFunction[invocationStats.HashFunction],
-
-			ColdStartBusyLoopMs: generator.ComputeBusyLoopPeriod(generator.GenerateMemorySpec(gen, gen.Float64(), memoryByHashFunction[invocationStats.HashFunction])),
 		}
 
+		gen := nameFunction(p.Seed, function)
+		function.ColdStartBusyLoopMs = generator.ComputeBusyLoopPeriod(generator.GenerateMemorySpec(gen, gen.Float64(), function.MemoryStats))
+
 		result = append(result, function)
 	}
 
+	checkFunctionNames(result)
+
 	return result
 }
 
 func (p *AzureTraceParser) Parse() []*common.Function {
-	invocationPath := p.DirectoryPath + "/invocations.csv"
-	runtimePath := p.DirectoryPath + "/durations.csv"
-	memoryPath := p.DirectoryPath + "/memory.csv"
+	invocationPath := p.DirectoryPath + "/" + p.InvocationsFile
+	runtimePath := p.DirectoryPath + "/" + p.DurationsFile
+	memoryPath := p.DirectoryPath + "/" + p.MemoryFile
 
 	invocationTrace := parseInvocationTrace(invocationPath, p.duration)
 	runtimeTrace := parseRuntimeTrace(runtimePath)
@@ -125,6 +134,8 @@ func (p *AzureTraceParser) Parse() []*common.Function {
 	return p.extractFunctions(invocationTrace, runtimeTrace, memoryTrace)
 }
 
+// parseInvocationTrace streams the rows of the invocation trace and keeps only the invocation counts of the first
+// traceDuration minutes of each function
 func parseInvocationTrace(traceFile string, traceDuration int) *[]common.FunctionInvocationStats {
 	log.Infof("Parsing function invocation trace %s (duration: %d min)", traceFile, traceDuration)
 
@@ -133,15 +144,14 @@ func parseInvocationTrace(traceFile string, traceDuration int) *[]common.Functio
 
 	var result []common.FunctionInvocationStats
 
-	invocationIndices := make([][]int, traceDuration)
-	totalInvocations := make([]int, traceDuration)
-
 	csvfile, err := os.Open(traceFile)
 	if err != nil {
 		log.Fatal("Failed to open invocation CSV file.", err)
 	}
+	defer csvfile.Close()
 
 	reader := csv.NewReader(csvfile)
+	reader.ReuseRecord = true
 
 	rowID := -1
 	hashOwnerIndex, hashAppIndex, hashFunctionIndex, invocationColumnIndex := -1, -1, -1, -1
@@ -166,7 +176,7 @@ func parseInvocationTrace(traceFile string, traceDuration int) *[]common.Functio
 					hashAppIndex = i
 				case "hashfunction":
 					hashFunctionIndex = i
-				case "trigger": //! Unused field.
+				case "trigger":
 					invocationColumnIndex = i + 1
 				}
 			}
@@ -180,26 +190,22 @@ func parseInvocationTrace(traceFile string, traceDuration int) *[]common.Functio
 			}
 		} else {
 			// Parse invocations
-			var invocations []int
+			invocations := make([]int, traceDuration)
 
 			for i := invocationColumnIndex; i < invocationColumnIndex+traceDuration; i++ {
-				minute := i - invocationColumnIndex
 				num, err := strconv.Atoi(record[i])
 				common.Check(err)
 
-				invocations = append(invocations, num)
-
-				for j := 0; j < num; j++ {
-					invocationIndices[minute] = append(invocationIndices[minute], rowID)
-				}
-				totalInvocations[minute] = totalInvocations[minute] + num
+				invocations[i-invocationColumnIndex] = num
 			}
 
+			// the fields of a record share the memory of the whole row, so they are cloned not to keep all the
+			// minutes of the trace in memory
 			result = append(result, common.FunctionInvocationStats{
-				HashOwner:    record[hashOwnerIndex],
-				HashApp:      record[hashAppIndex],
-				HashFunction: record[hashFunctionIndex],
-				Trigger:      record[invocationColumnIndex-1],
+				HashOwner:    strings.Clone(record[hashOwnerIndex]),
+				HashApp:      strings.Clone(record[hashAppIndex]),
+				HashFunction: strings.Clone(record[hashFunctionIndex]),
+				Trigger:      strings.Clone(record[invocationColumnIndex-1]),
 				Invocations:  invocations,
 			})
 		}
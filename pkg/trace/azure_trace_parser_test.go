@@ -134,3 +134,21 @@ func TestParserWrapper(t *testing.T) {
 		t.Error("Unexpected results.")
 	}
 }
+
+func TestParserDeterministicFunctions(t *testing.T) {
+	parse := func(seed int64) *common.Function {
+		parser := NewAzureParser("test_data", 10)
+		parser.Seed = seed
+
+		return parser.Parse()[0]
+	}
+
+	first, second, other := parse(42), parse(42), parse(7)
+
+	if first.Name != second.Name || first.ColdStartBusyLoopMs != second.ColdStartBusyLoopMs {
+		t.Errorf("Functions differ with the same seed - got: %s and %s.", first.Name, second.Name)
+	}
+	if first.Name == other.Name {
+		t.Errorf("Function name does not depend on the seed - got: %s.", first.Name)
+	}
+}
@@ -0,0 +1,213 @@
+package trace
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/generator"
+)
+
+const DefaultCSVTraceFile = "trace.csv"
+
+const (
+	runtimeColumnPrefix = "Runtime."
+	memoryColumnPrefix  = "Memory."
+)
+
+// CSVTraceParser reads a CSV trace with one row per function whose columns are mapped to the fields of the trace.
+// The keys of the column mapping are HashOwner, HashApp, HashFunction, Trigger, Invocations, which is the column of
+// the first minute followed by the columns of the next minutes, and the fields of FunctionRuntimeStats and
+// FunctionMemoryStats prefixed by Runtime. and Memory., e.g., Runtime.Average or Memory.Percentile99. HashFunction,
+// Invocations and Runtime.Average are mandatory, while the runtime and the memory fields that are not mapped default
+// to the corresponding average, or to DefaultTraceMemory for the memory of traces without memory columns.
+type CSVTraceParser struct {
+	DirectoryPath string
+	FileName      string
+	ColumnMapping map[string]string
+	// Seed from which the names and the cold start busy loops of the functions are derived
+	Seed int64
+
+	duration int
+}
+
+func NewCSVTraceParser(directoryPath string, fileName string, columnMapping map[string]string, totalDuration int) *CSVTraceParser {
+	if fileName == "" {
+		fileName = DefaultCSVTraceFile
+	}
+
+	return &CSVTraceParser{
+		DirectoryPath: directoryPath,
+		FileName:      fileName,
+		ColumnMapping: columnMapping,
+
+		duration: totalDuration,
+	}
+}
+
+// resolveColumns returns the index of the column of each of the mapped fields
+func (p *CSVTraceParser) resolveColumns(header []string) map[string]int {
+	indexByName := make(map[string]int)
+	for i, name := range header {
+		indexByName[strings.TrimSpace(name)] = i
+	}
+
+	result := make(map[string]int)
+	for field, column := range p.ColumnMapping {
+		index, ok := indexByName[column]
+		if !ok {
+			log.Fatalf("Column %s mapped to %s not found in the trace.", column, field)
+		}
+
+		switch {
+		case field == "HashOwner" || field == "HashApp" || field == "HashFunction" || field == "Trigger" || field == "Invocations":
+		case strings.HasPrefix(field, runtimeColumnPrefix) && isStatsField(common.FunctionRuntimeStats{}, field[len(runtimeColumnPrefix):]):
+		case strings.HasPrefix(field, memoryColumnPrefix) && isStatsField(common.FunctionMemoryStats{}, field[len(memoryColumnPrefix):]):
+		default:
+			log.Fatalf("Unsupported field %s in the column mapping of the trace.", field)
+		}
+
+		result[field] = index
+	}
+
+	for _, field := range []string{"HashFunction", "Invocations", runtimeColumnPrefix + "Average"} {
+		if _, ok := result[field]; !ok {
+			log.Fatalf("Column mapping of the trace does not contain the mandatory field %s.", field)
+		}
+	}
+
+	return result
+}
+
+func isStatsField(stats interface{}, name string) bool {
+	field, ok := reflect.TypeOf(stats).FieldByName(name)
+
+	return ok && field.Type.Kind() == reflect.Float64
+}
+
+// fillStats sets the numerical fields of the stats from the mapped columns of the record. The fields that are not
+// mapped are set to the average, or to defaultAverage if the average is not mapped either, except for the count, which
+// is set to 1 as the runtime and memory specifications are generated only from stats with a positive count.
+func fillStats(stats interface{}, prefix string, columns map[string]int, record []string, defaultAverage float64) {
+	value := reflect.ValueOf(stats).Elem()
+
+	average := defaultAverage
+	if index, ok := columns[prefix+"Average"]; ok {
+		average = parseTraceFloat(record[index])
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Type.Kind() != reflect.Float64 {
+			continue
+		}
+
+		if index, ok := columns[prefix+field.Name]; ok {
+			value.Field(i).SetFloat(parseTraceFloat(record[index]))
+		} else if field.Name == "Count" {
+			value.Field(i).SetFloat(1)
+		} else {
+			value.Field(i).SetFloat(average)
+		}
+	}
+}
+
+func parseTraceFloat(value string) float64 {
+	result, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	common.Check(err)
+
+	return result
+}
+
+func (p *CSVTraceParser) Parse() []*common.Function {
+	traceFile := p.DirectoryPath + "/" + p.FileName
+	log.Infof("Parsing CSV trace %s (duration: %d min)", traceFile, p.duration)
+
+	f, err := os.Open(traceFile)
+	if err != nil {
+		log.Fatal("Failed to open CSV trace file.", err)
+	}
+	defer f.Close()
+
+	// rows are streamed one at a time, so that only the parsed fields of each function are kept in memory
+	reader := csv.NewReader(f)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		log.Fatal("CSV trace does not contain a header.", err)
+	}
+
+	columns := p.resolveColumns(header)
+	firstMinute := columns["Invocations"]
+	if firstMinute+p.duration > len(header) {
+		log.Fatalf("CSV trace contains less than %d minutes of invocations.", p.duration)
+	}
+
+	text := func(record []string, field string) string {
+		if index, ok := columns[field]; ok {
+			return strings.Clone(record[index])
+		}
+
+		return ""
+	}
+
+	var result []*common.Function
+	for i := 0; ; i++ {
+		record, err := reader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			log.Fatal(err)
+		}
+
+		invocationStats := &common.FunctionInvocationStats{
+			HashOwner:    text(record, "HashOwner"),
+			HashApp:      text(record, "HashApp"),
+			HashFunction: text(record, "HashFunction"),
+			Trigger:      text(record, "Trigger"),
+		}
+		invocationStats.Invocations = make([]int, p.duration)
+		for minute := 0; minute < p.duration; minute++ {
+			count, err := strconv.Atoi(strings.TrimSpace(record[firstMinute+minute]))
+			common.Check(err)
+
+			invocationStats.Invocations[minute] = count
+		}
+
+		runtimeStats := &common.FunctionRuntimeStats{
+			HashOwner:    invocationStats.HashOwner,
+			HashApp:      invocationStats.HashApp,
+			HashFunction: invocationStats.HashFunction,
+		}
+		fillStats(runtimeStats, runtimeColumnPrefix, columns, record, 0)
+
+		memoryStats := &common.FunctionMemoryStats{
+			HashOwner:    invocationStats.HashOwner,
+			HashApp:      invocationStats.HashApp,
+			HashFunction: invocationStats.HashFunction,
+		}
+		fillStats(memoryStats, memoryColumnPrefix, columns, record, DefaultTraceMemory)
+
+		function := &common.Function{
+			InvocationStats: invocationStats,
+			RuntimeStats:    runtimeStats,
+			MemoryStats:     memoryStats,
+		}
+
+		gen := nameFunction(p.Seed, function)
+		function.ColdStartBusyLoopMs = generator.ComputeBusyLoopPeriod(generator.GenerateMemorySpec(gen, gen.Float64(), memoryStats))
+
+		result = append(result, function)
+	}
+
+	checkFunctionNames(result)
+
+	return result
+}
@@ -0,0 +1,281 @@
+package trace
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/generator"
+	"gonum.org/v1/gonum/stat"
+)
+
+const DefaultTimestampTraceFile = "invocation_timestamps.csv"
+
+// DefaultTraceMemory is the memory in MiB of the functions of traces without memory information
+const DefaultTraceMemory = 128
+
+// TimestampTraceParser reads invocation-level traces, such as the Azure Functions 2021 trace, where each row holds the
+// app, the function, the end timestamp and the duration of a single invocation in seconds. The IATs and the runtimes
+// of the invocations are replayed exactly instead of being generated from per-minute counts and a distribution.
+type TimestampTraceParser struct {
+	DirectoryPath string
+	FileName      string
+	// Seed from which the names of the functions are derived
+	Seed int64
+
+	duration int
+}
+
+type timestampedInvocation struct {
+	start    float64 // s
+	duration float64 // s
+	memory   int     // MiB
+}
+
+type timestampedFunction struct {
+	hashOwner    string
+	hashApp      string
+	hashFunction string
+
+	invocations []timestampedInvocation
+}
+
+func NewTimestampTraceParser(directoryPath string, fileName string, totalDuration int) *TimestampTraceParser {
+	if fileName == "" {
+		fileName = DefaultTimestampTraceFile
+	}
+
+	return &TimestampTraceParser{
+		DirectoryPath: directoryPath,
+		FileName:      fileName,
+
+		duration: totalDuration,
+	}
+}
+
+func (p *TimestampTraceParser) Parse() []*common.Function {
+	traceFile := p.DirectoryPath + "/" + p.FileName
+	log.Infof("Parsing invocation timestamp trace %s (duration: %d min)", traceFile, p.duration)
+
+	functions, traceStart := parseTimestampTrace(traceFile)
+
+	var result []*common.Function
+	for _, function := range functions {
+		spec := createTimestampSpecification(function, traceStart, p.duration)
+		if len(spec.IAT) == 0 {
+			// the function would be deployed without ever being invoked
+			continue
+		}
+
+		runtimeStats, memoryStats := createTimestampStats(function, spec)
+
+		parsed := &common.Function{
+			InvocationStats: &common.FunctionInvocationStats{
+				HashOwner:    function.hashOwner,
+				HashApp:      function.hashApp,
+				HashFunction: function.hashFunction,
+				Invocations:  spec.PerMinuteCount,
+			},
+			RuntimeStats: runtimeStats,
+			MemoryStats:  memoryStats,
+
+			ColdStartBusyLoopMs: generator.ComputeBusyLoopPeriod(int(memoryStats.Percentile100)),
+
+			Specification:       spec,
+			ReplaySpecification: true,
+		}
+		nameFunction(p.Seed, parsed)
+
+		result = append(result, parsed)
+	}
+
+	checkFunctionNames(result)
+	if skipped := len(functions) - len(result); skipped > 0 {
+		log.Infof("Skipped %d functions without invocations in the first %d minutes of the trace.", skipped, p.duration)
+	}
+
+	return result
+}
+
+// parseTimestampTrace groups the invocations of the trace by function and returns them together with the beginning
+// of the minute of the earliest invocation, which is taken as the beginning of the trace
+func parseTimestampTrace(traceFile string) ([]*timestampedFunction, float64) {
+	csvfile, err := os.Open(traceFile)
+	if err != nil {
+		log.Fatal("Failed to open invocation timestamp CSV file.", err)
+	}
+	defer csvfile.Close()
+
+	reader := csv.NewReader(csvfile)
+
+	var result []*timestampedFunction
+	functionsByKey := make(map[string]*timestampedFunction)
+	traceStart := math.Inf(1)
+
+	ownerIndex, appIndex, functionIndex, endIndex, durationIndex, memoryIndex := -1, -1, -1, -1, -1, -1
+	for rowID := 0; ; rowID++ {
+		record, err := reader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			log.Fatal(err)
+		}
+
+		if rowID == 0 {
+			for i, column := range record {
+				switch strings.ToLower(strings.TrimSpace(column)) {
+				case "owner", "hashowner":
+					ownerIndex = i
+				case "app", "hashapp":
+					appIndex = i
+				case "func", "function", "hashfunction":
+					functionIndex = i
+				case "end_timestamp":
+					endIndex = i
+				case "duration":
+					durationIndex = i
+				case "memory":
+					memoryIndex = i
+				}
+			}
+
+			if appIndex == -1 || functionIndex == -1 || endIndex == -1 || durationIndex == -1 {
+				log.Fatal("Invocation timestamp trace should contain the app, func, end_timestamp and duration columns.")
+			}
+
+			continue
+		}
+
+		end, err := strconv.ParseFloat(record[endIndex], 64)
+		common.Check(err)
+		duration, err := strconv.ParseFloat(record[durationIndex], 64)
+		common.Check(err)
+
+		memory := DefaultTraceMemory
+		if memoryIndex != -1 {
+			memory, err = strconv.Atoi(record[memoryIndex])
+			common.Check(err)
+		}
+
+		owner := ""
+		if ownerIndex != -1 {
+			owner = record[ownerIndex]
+		}
+
+		key := owner + "/" + record[appIndex] + "/" + record[functionIndex]
+		function, ok := functionsByKey[key]
+		if !ok {
+			function = &timestampedFunction{
+				hashOwner:    owner,
+				hashApp:      record[appIndex],
+				hashFunction: record[functionIndex],
+			}
+
+			functionsByKey[key] = function
+			result = append(result, function)
+		}
+
+		invocation := timestampedInvocation{start: end - duration, duration: duration, memory: memory}
+		function.invocations = append(function.invocations, invocation)
+		traceStart = math.Min(traceStart, invocation.start)
+	}
+
+	return result, math.Floor(traceStart/60) * 60
+}
+
+// createTimestampSpecification converts the invocations of the first traceDuration minutes of the trace into IATs in
+// μs and runtimes in ms
+func createTimestampSpecification(function *timestampedFunction, traceStart float64, traceDuration int) *common.FunctionSpecification {
+	sort.SliceStable(function.invocations, func(i, j int) bool {
+		return function.invocations[i].start < function.invocations[j].start
+	})
+
+	spec := &common.FunctionSpecification{
+		PerMinuteCount: make([]int, traceDuration),
+	}
+
+	previous := 0.0
+	for _, invocation := range function.invocations {
+		start := (invocation.start - traceStart) * 1_000_000
+		minute := int(start / 60_000_000)
+		if minute >= traceDuration {
+			break
+		}
+
+		spec.IAT = append(spec.IAT, start-previous)
+		spec.PerMinuteCount[minute]++
+		spec.RuntimeSpecification = append(spec.RuntimeSpecification, common.RuntimeSpecification{
+			Runtime: max(int(math.Round(invocation.duration*1000)), 1),
+			Memory:  invocation.memory,
+		})
+
+		previous = start
+	}
+
+	return spec
+}
+
+// createTimestampStats summarizes the runtime and the memory of the replayed invocations
+func createTimestampStats(function *timestampedFunction, spec *common.FunctionSpecification) (*common.FunctionRuntimeStats, *common.FunctionMemoryStats) {
+	var runtimes, memory []float64
+	for _, runtime := range spec.RuntimeSpecification {
+		runtimes = append(runtimes, float64(runtime.Runtime))
+		memory = append(memory, float64(runtime.Memory))
+	}
+
+	sort.Float64s(runtimes)
+	sort.Float64s(memory)
+
+	runtimeQuantile := func(p float64) float64 {
+		return stat.Quantile(p, stat.Empirical, runtimes, nil)
+	}
+	memoryQuantile := func(p float64) float64 {
+		return stat.Quantile(p, stat.Empirical, memory, nil)
+	}
+
+	runtimeStats := &common.FunctionRuntimeStats{
+		HashOwner:    function.hashOwner,
+		HashApp:      function.hashApp,
+		HashFunction: function.hashFunction,
+
+		Average: stat.Mean(runtimes, nil),
+		Count:   float64(len(runtimes)),
+		Minimum: runtimes[0],
+		Maximum: runtimes[len(runtimes)-1],
+
+		Percentile0:   runtimes[0],
+		Percentile1:   runtimeQuantile(0.01),
+		Percentile25:  runtimeQuantile(0.25),
+		Percentile50:  runtimeQuantile(0.50),
+		Percentile75:  runtimeQuantile(0.75),
+		Percentile99:  runtimeQuantile(0.99),
+		Percentile100: runtimes[len(runtimes)-1],
+	}
+
+	memoryStats := &common.FunctionMemoryStats{
+		HashOwner:    function.hashOwner,
+		HashApp:      function.hashApp,
+		HashFunction: function.hashFunction,
+
+		Count:   float64(len(memory)),
+		Average: stat.Mean(memory, nil),
+
+		Percentile1:   memoryQuantile(0.01),
+		Percentile5:   memoryQuantile(0.05),
+		Percentile25:  memoryQuantile(0.25),
+		Percentile50:  memoryQuantile(0.50),
+		Percentile75:  memoryQuantile(0.75),
+		Percentile95:  memoryQuantile(0.95),
+		Percentile99:  memoryQuantile(0.99),
+		Percentile100: memory[len(memory)-1],
+	}
+
+	return runtimeStats, memoryStats
+}
@@ -0,0 +1,100 @@
+package trace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vhive-serverless/loader/pkg/config"
+)
+
+func TestTimestampTraceParser(t *testing.T) {
+	// funcC is invoked only after the first 3 minutes of the trace
+	functions := NewTimestampTraceParser("test_data", "", 3).Parse()
+
+	if len(functions) != 2 {
+		t.Fatalf("Unexpected number of functions - got: %d, expected: 2.", len(functions))
+	}
+	if longer := NewTimestampTraceParser("test_data", "", 5).Parse(); len(longer) != 3 {
+		t.Errorf("Unexpected number of functions in 5 minutes - got: %d, expected: 3.", len(longer))
+	}
+
+	tests := []struct {
+		hashApp        string
+		hashFunction   string
+		iat            []float64
+		perMinuteCount []int
+		runtimes       []int
+	}{
+		{
+			hashApp:        "app1",
+			hashFunction:   "funcA",
+			iat:            []float64{250_000, 550_000, 59_300_000, 400_000},
+			perMinuteCount: []int{2, 2, 0},
+			runtimes:       []int{250, 100, 800, 500},
+		},
+		{
+			hashApp:        "app2",
+			hashFunction:   "funcB",
+			iat:            []float64{28_000_000, 96_000_000},
+			perMinuteCount: []int{1, 0, 1},
+			runtimes:       []int{2000, 1000},
+		},
+	}
+
+	for i, test := range tests {
+		function := functions[i]
+		spec := function.Specification
+
+		if !function.ReplaySpecification {
+			t.Error("Specification of the trace should be replayed.")
+		}
+		if function.InvocationStats.HashApp != test.hashApp || function.InvocationStats.HashFunction != test.hashFunction {
+			t.Errorf("Unexpected function %s/%s.", function.InvocationStats.HashApp, function.InvocationStats.HashFunction)
+		}
+
+		if len(spec.IAT) != len(test.iat) || len(spec.RuntimeSpecification) != len(test.runtimes) {
+			t.Fatalf("Function %s: unexpected number of invocations - got: %d, expected: %d.", test.hashFunction, len(spec.IAT), len(test.iat))
+		}
+		for j := range test.iat {
+			if !floatEqual(spec.IAT[j], test.iat[j]) {
+				t.Errorf("Function %s: unexpected IAT %d - got: %f, expected: %f.", test.hashFunction, j, spec.IAT[j], test.iat[j])
+			}
+			if spec.RuntimeSpecification[j].Runtime != test.runtimes[j] || spec.RuntimeSpecification[j].Memory != DefaultTraceMemory {
+				t.Errorf("Function %s: unexpected runtime specification %d - %+v.", test.hashFunction, j, spec.RuntimeSpecification[j])
+			}
+		}
+
+		for minute := range test.perMinuteCount {
+			if spec.PerMinuteCount[minute] != test.perMinuteCount[minute] || function.InvocationStats.Invocations[minute] != test.perMinuteCount[minute] {
+				t.Errorf("Function %s: unexpected number of invocations in minute %d.", test.hashFunction, minute)
+			}
+		}
+	}
+
+	if !floatEqual(functions[0].RuntimeStats.Average, 412.5) || !floatEqual(functions[0].RuntimeStats.Maximum, 800) ||
+		!floatEqual(functions[0].MemoryStats.Percentile100, DefaultTraceMemory) {
+		t.Error("Unexpected runtime or memory statistics.")
+	}
+}
+
+func TestTimestampTraceParserFileName(t *testing.T) {
+	trace, err := os.ReadFile("test_data/invocation_timestamps.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	directory := t.TempDir()
+	if err = os.WriteFile(filepath.Join(directory, "azure_2021.csv"), trace, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	functions := NewTraceParser(&config.LoaderConfiguration{
+		TraceFormat: "timestamps",
+		TracePath:   directory,
+		TraceFile:   "azure_2021.csv",
+	}, 3).Parse()
+	if len(functions) != 2 {
+		t.Errorf("Unexpected number of functions - got: %d, expected: 2.", len(functions))
+	}
+}
@@ -0,0 +1,207 @@
+package trace
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	"gonum.org/v1/gonum/stat"
+)
+
+type traceFilter struct {
+	name   string
+	keep   func(function *common.Function) bool
+	active bool
+}
+
+func totalInvocations(function *common.Function) int {
+	result := 0
+	for _, count := range function.InvocationStats.Invocations {
+		result += count
+	}
+
+	return result
+}
+
+func invocationsPerMinute(function *common.Function) float64 {
+	if len(function.InvocationStats.Invocations) == 0 {
+		return 0
+	}
+
+	return float64(totalInvocations(function)) / float64(len(function.InvocationStats.Invocations))
+}
+
+func containsFold(values []string, value string) bool {
+	return slices.ContainsFunc(values, func(v string) bool {
+		return strings.EqualFold(v, value)
+	})
+}
+
+// runtimeBand returns the average runtimes in ms that bound the given band of percentiles of the average runtimes of
+// the functions
+func runtimeBand(functions []*common.Function, band []float64) (float64, float64) {
+	var runtimes []float64
+	for _, function := range functions {
+		if function.RuntimeStats != nil {
+			runtimes = append(runtimes, function.RuntimeStats.Average)
+		}
+	}
+	if len(runtimes) == 0 {
+		return 0, 0
+	}
+	sort.Float64s(runtimes)
+
+	return stat.Quantile(band[0]/100, stat.Empirical, runtimes, nil), stat.Quantile(band[1]/100, stat.Empirical, runtimes, nil)
+}
+
+// FilterFunctions selects the functions of the trace according to the filters of the configuration and logs a summary
+// of the selection. The filters are applied in the order of the trigger, the owner, the app, the invocation rate band,
+// the runtime percentile band and the top-N functions by number of invocations. It exits if no function is selected.
+func FilterFunctions(functions []*common.Function, cfg *config.LoaderConfiguration) []*common.Function {
+	if len(cfg.RuntimePercentileBand) != 0 && (len(cfg.RuntimePercentileBand) != 2 || cfg.RuntimePercentileBand[0] < 0 ||
+		cfg.RuntimePercentileBand[1] > 100 || cfg.RuntimePercentileBand[0] > cfg.RuntimePercentileBand[1]) {
+		log.Fatal("RuntimePercentileBand should contain the lower and the upper percentile between 0 and 100.")
+	}
+	if len(cfg.InvocationsPerMinuteBand) != 0 && (len(cfg.InvocationsPerMinuteBand) != 2 || cfg.InvocationsPerMinuteBand[0] < 0 ||
+		(cfg.InvocationsPerMinuteBand[1] > 0 && cfg.InvocationsPerMinuteBand[0] > cfg.InvocationsPerMinuteBand[1])) {
+		log.Fatal("InvocationsPerMinuteBand should contain the lower and the upper number of invocations per minute.")
+	}
+	if cfg.TopNFunctions < 0 {
+		log.Fatal("TopNFunctions should be positive.")
+	}
+
+	// the runtime band is determined from the whole trace, so that it does not depend on the other filters
+	minRuntime, maxRuntime := 0.0, 0.0
+	if len(cfg.RuntimePercentileBand) == 2 {
+		minRuntime, maxRuntime = runtimeBand(functions, cfg.RuntimePercentileBand)
+	}
+
+	filters := []traceFilter{
+		{
+			name:   fmt.Sprintf("trigger in %v", cfg.IncludeTriggers),
+			active: len(cfg.IncludeTriggers) > 0,
+			keep: func(function *common.Function) bool {
+				return containsFold(cfg.IncludeTriggers, function.InvocationStats.Trigger)
+			},
+		},
+		{
+			name:   fmt.Sprintf("trigger not in %v", cfg.ExcludeTriggers),
+			active: len(cfg.ExcludeTriggers) > 0,
+			keep: func(function *common.Function) bool {
+				return !containsFold(cfg.ExcludeTriggers, function.InvocationStats.Trigger)
+			},
+		},
+		{
+			name:   fmt.Sprintf("%d owners", len(cfg.IncludeOwners)),
+			active: len(cfg.IncludeOwners) > 0,
+			keep: func(function *common.Function) bool {
+				return slices.Contains(cfg.IncludeOwners, function.InvocationStats.HashOwner)
+			},
+		},
+		{
+			name:   fmt.Sprintf("%d apps", len(cfg.IncludeApps)),
+			active: len(cfg.IncludeApps) > 0,
+			keep: func(function *common.Function) bool {
+				return slices.Contains(cfg.IncludeApps, function.InvocationStats.HashApp)
+			},
+		},
+		{
+			name:   fmt.Sprintf("invocations per minute in %v", cfg.InvocationsPerMinuteBand),
+			active: len(cfg.InvocationsPerMinuteBand) == 2,
+			keep: func(function *common.Function) bool {
+				ipm := invocationsPerMinute(function)
+				return ipm >= cfg.InvocationsPerMinuteBand[0] && (cfg.InvocationsPerMinuteBand[1] <= 0 || ipm <= cfg.InvocationsPerMinuteBand[1])
+			},
+		},
+		{
+			name:   fmt.Sprintf("average runtime in [%.1f, %.1f] ms", minRuntime, maxRuntime),
+			active: len(cfg.RuntimePercentileBand) == 2,
+			keep: func(function *common.Function) bool {
+				return function.RuntimeStats != nil && function.RuntimeStats.Average >= minRuntime && function.RuntimeStats.Average <= maxRuntime
+			},
+		},
+	}
+
+	result := functions
+	for _, filter := range filters {
+		if !filter.active {
+			continue
+		}
+
+		var kept []*common.Function
+		for _, function := range result {
+			if filter.keep(function) {
+				kept = append(kept, function)
+			}
+		}
+
+		log.Infof("Filter %s kept %d out of %d functions.", filter.name, len(kept), len(result))
+		result = kept
+	}
+
+	if cfg.TopNFunctions > 0 && cfg.TopNFunctions < len(result) {
+		sorted := slices.Clone(result)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return totalInvocations(sorted[i]) > totalInvocations(sorted[j])
+		})
+		top := make(map[*common.Function]struct{}, cfg.TopNFunctions)
+		for _, function := range sorted[:cfg.TopNFunctions] {
+			top[function] = struct{}{}
+		}
+
+		// the selected functions keep their order in the trace
+		var kept []*common.Function
+		for _, function := range result {
+			if _, ok := top[function]; ok {
+				kept = append(kept, function)
+			}
+		}
+
+		log.Infof("Filter top %d functions by invocations kept %d out of %d functions.", cfg.TopNFunctions, len(kept), len(result))
+		result = kept
+	}
+
+	logTraceSummary(functions, result)
+	if len(result) == 0 {
+		log.Fatal("No function of the trace satisfies the filters.")
+	}
+
+	return result
+}
+
+func logTraceSummary(all []*common.Function, kept []*common.Function) {
+	if len(all) == len(kept) {
+		return
+	}
+
+	allInvocations, keptInvocations := 0, 0
+	for _, function := range all {
+		allInvocations += totalInvocations(function)
+	}
+
+	perTrigger := make(map[string]int)
+	for _, function := range kept {
+		keptInvocations += totalInvocations(function)
+
+		trigger := function.InvocationStats.Trigger
+		if trigger == "" {
+			trigger = "unknown"
+		}
+		perTrigger[trigger]++
+	}
+
+	var triggers []string
+	for trigger, count := range perTrigger {
+		triggers = append(triggers, fmt.Sprintf("%s: %d", trigger, count))
+	}
+	sort.Strings(triggers)
+
+	log.Infof("Kept %d out of %d functions with %d out of %d invocations of the trace.", len(kept), len(all), keptInvocations, allInvocations)
+	if len(triggers) > 0 {
+		log.Infof("Kept functions per trigger - %s", strings.Join(triggers, ", "))
+	}
+}
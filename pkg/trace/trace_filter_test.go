@@ -0,0 +1,101 @@
+package trace
+
+import (
+	"testing"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+)
+
+func createFilterTestFunctions() []*common.Function {
+	create := func(name string, owner string, app string, trigger string, invocations []int, runtime float64) *common.Function {
+		return &common.Function{
+			Name: name,
+			InvocationStats: &common.FunctionInvocationStats{
+				HashOwner:    owner,
+				HashApp:      app,
+				HashFunction: name,
+				Trigger:      trigger,
+				Invocations:  invocations,
+			},
+			RuntimeStats: &common.FunctionRuntimeStats{Average: runtime},
+		}
+	}
+
+	return []*common.Function{
+		create("f0", "o0", "a0", "http", []int{1, 1}, 10),
+		create("f1", "o0", "a1", "timer", []int{5, 5}, 20),
+		create("f2", "o1", "a2", "queue", []int{10, 20}, 30),
+		create("f3", "o1", "a3", "http", []int{100, 100}, 40),
+		create("f4", "o2", "a4", "HTTP", []int{0, 2}, 50),
+	}
+}
+
+func TestFilterFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.LoaderConfiguration
+		expected []string
+	}{
+		{
+			name:     "no_filters",
+			cfg:      config.LoaderConfiguration{},
+			expected: []string{"f0", "f1", "f2", "f3", "f4"},
+		},
+		{
+			name:     "include_triggers",
+			cfg:      config.LoaderConfiguration{IncludeTriggers: []string{"http"}},
+			expected: []string{"f0", "f3", "f4"},
+		},
+		{
+			name:     "exclude_triggers",
+			cfg:      config.LoaderConfiguration{ExcludeTriggers: []string{"http", "queue"}},
+			expected: []string{"f1"},
+		},
+		{
+			name:     "owners_and_apps",
+			cfg:      config.LoaderConfiguration{IncludeOwners: []string{"o0", "o1"}, IncludeApps: []string{"a1", "a2", "a4"}},
+			expected: []string{"f1", "f2"},
+		},
+		{
+			name:     "top_n_keeps_trace_order",
+			cfg:      config.LoaderConfiguration{TopNFunctions: 2},
+			expected: []string{"f2", "f3"},
+		},
+		{
+			name:     "top_n_after_trigger",
+			cfg:      config.LoaderConfiguration{IncludeTriggers: []string{"http"}, TopNFunctions: 2},
+			expected: []string{"f0", "f3"},
+		},
+		{
+			name:     "invocations_per_minute_band",
+			cfg:      config.LoaderConfiguration{InvocationsPerMinuteBand: []float64{1, 15}},
+			expected: []string{"f0", "f1", "f2", "f4"},
+		},
+		{
+			name:     "invocations_per_minute_unbounded",
+			cfg:      config.LoaderConfiguration{InvocationsPerMinuteBand: []float64{10, 0}},
+			expected: []string{"f2", "f3"},
+		},
+		{
+			name:     "runtime_percentile_band",
+			cfg:      config.LoaderConfiguration{RuntimePercentileBand: []float64{25, 75}},
+			expected: []string{"f1", "f2", "f3"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			functions := FilterFunctions(createFilterTestFunctions(), &test.cfg)
+
+			if len(functions) != len(test.expected) {
+				t.Fatalf("Unexpected number of functions - got: %d, expected: %d.", len(functions), len(test.expected))
+			}
+			for i, function := range functions {
+				if function.Name != test.expected[i] {
+					t.Errorf("Unexpected function %d - got: %s, expected: %s.", i, function.Name, test.expected[i])
+				}
+			}
+		})
+	}
+}
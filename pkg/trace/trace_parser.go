@@ -0,0 +1,123 @@
+package trace
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	"github.com/vhive-serverless/loader/pkg/generator"
+)
+
+// TraceParser reads the functions of a trace together with their invocation, runtime and memory statistics
+type TraceParser interface {
+	Parse() []*common.Function
+}
+
+// TraceParserFactory creates a parser reading the first duration minutes of the trace described by the configuration
+type TraceParserFactory func(cfg *config.LoaderConfiguration, duration int) TraceParser
+
+const DefaultTraceFormat = "azure"
+
+var (
+	traceParsersMutex sync.RWMutex
+	traceParsers      = map[string]TraceParserFactory{
+		"azure": func(cfg *config.LoaderConfiguration, duration int) TraceParser {
+			parser := NewAzureParser(cfg.TracePath, duration)
+			parser.Seed = cfg.Seed
+			if cfg.TraceInvocationsFile != "" {
+				parser.InvocationsFile = cfg.TraceInvocationsFile
+			}
+			if cfg.TraceDurationsFile != "" {
+				parser.DurationsFile = cfg.TraceDurationsFile
+			}
+			if cfg.TraceMemoryFile != "" {
+				parser.MemoryFile = cfg.TraceMemoryFile
+			}
+
+			return parser
+		},
+		"timestamps": func(cfg *config.LoaderConfiguration, duration int) TraceParser {
+			parser := NewTimestampTraceParser(cfg.TracePath, cfg.TraceFile, duration)
+			parser.Seed = cfg.Seed
+
+			return parser
+		},
+		"csv": func(cfg *config.LoaderConfiguration, duration int) TraceParser {
+			parser := NewCSVTraceParser(cfg.TracePath, cfg.TraceFile, cfg.TraceColumnMapping, duration)
+			parser.Seed = cfg.Seed
+
+			return parser
+		},
+	}
+)
+
+// RegisterTraceParser makes a trace format available through the TraceFormat key of the configuration
+func RegisterTraceParser(format string, factory TraceParserFactory) {
+	traceParsersMutex.Lock()
+	defer traceParsersMutex.Unlock()
+
+	if _, ok := traceParsers[format]; ok {
+		log.Fatalf("Trace format %s has already been registered.", format)
+	}
+
+	traceParsers[format] = factory
+}
+
+// TraceFormats returns the names of the registered trace formats
+func TraceFormats() []string {
+	traceParsersMutex.RLock()
+	defer traceParsersMutex.RUnlock()
+
+	var result []string
+	for format := range traceParsers {
+		result = append(result, format)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// NewTraceParser creates the parser of the format selected by TraceFormat, the Azure 2019 format by default
+func NewTraceParser(cfg *config.LoaderConfiguration, duration int) TraceParser {
+	format := cfg.TraceFormat
+	if format == "" {
+		format = DefaultTraceFormat
+	}
+
+	traceParsersMutex.RLock()
+	factory, ok := traceParsers[format]
+	traceParsersMutex.RUnlock()
+
+	if !ok {
+		log.Fatalf("Unsupported trace format %s. Supported formats are %v.", format, TraceFormats())
+	}
+
+	return factory(cfg, duration)
+}
+
+// nameFunction names the function after the first draw of its random stream, which is derived from the seed and the
+// identity of the function, and returns the stream for the remaining draws of the parser
+func nameFunction(seed int64, function *common.Function) *rand.Rand {
+	gen := common.NewFunctionRand(seed, generator.FunctionIdentity(function))
+	function.Name = fmt.Sprintf("%s-%d", common.FunctionNamePrefix, gen.Uint64())
+
+	return gen
+}
+
+// checkFunctionNames exits if two functions of the trace have the same name, as they would be deployed as one
+func checkFunctionNames(functions []*common.Function) {
+	identities := make(map[string]string, len(functions))
+
+	for _, function := range functions {
+		identity := generator.FunctionIdentity(function)
+		if other, ok := identities[function.Name]; ok {
+			log.Fatalf("Functions %s and %s of the trace are both named %s.", other, identity, function.Name)
+		}
+
+		identities[function.Name] = identity
+	}
+}
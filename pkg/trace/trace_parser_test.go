@@ -0,0 +1,204 @@
+package trace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/config"
+	"github.com/vhive-serverless/loader/pkg/generator"
+)
+
+type fakeTraceParser struct {
+	duration int
+}
+
+func (p *fakeTraceParser) Parse() []*common.Function {
+	return []*common.Function{{Name: "fake", InvocationStats: &common.FunctionInvocationStats{Invocations: make([]int, p.duration)}}}
+}
+
+func TestTraceParserRegistry(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{format: "", expected: "*trace.AzureTraceParser"},
+		{format: "azure", expected: "*trace.AzureTraceParser"},
+		{format: "timestamps", expected: "*trace.TimestampTraceParser"},
+		{format: "csv", expected: "*trace.CSVTraceParser"},
+	}
+
+	for _, test := range tests {
+		parser := NewTraceParser(&config.LoaderConfiguration{TracePath: "test_data", TraceFormat: test.format}, 10)
+
+		if parserType := fmt.Sprintf("%T", parser); parserType != test.expected {
+			t.Errorf("Format %q: unexpected parser - got: %s, expected: %s.", test.format, parserType, test.expected)
+		}
+	}
+
+	RegisterTraceParser("fake", func(cfg *config.LoaderConfiguration, duration int) TraceParser {
+		return &fakeTraceParser{duration: duration}
+	})
+
+	if !slices.Contains(TraceFormats(), "fake") {
+		t.Error("Registered trace format is not listed.")
+	}
+
+	functions := NewTraceParser(&config.LoaderConfiguration{TraceFormat: "fake"}, 5).Parse()
+	if len(functions) != 1 || functions[0].Name != "fake" || len(functions[0].InvocationStats.Invocations) != 5 {
+		t.Error("Registered trace parser has not been used.")
+	}
+}
+
+func TestAzureTraceParserFileNames(t *testing.T) {
+	parser := NewTraceParser(&config.LoaderConfiguration{TracePath: "test_data"}, 10).(*AzureTraceParser)
+	if parser.InvocationsFile != DefaultAzureInvocationsFile || parser.DurationsFile != DefaultAzureDurationsFile || parser.MemoryFile != DefaultAzureMemoryFile {
+		t.Errorf("Unexpected default file names - %s, %s, %s.", parser.InvocationsFile, parser.DurationsFile, parser.MemoryFile)
+	}
+
+	parser = NewTraceParser(&config.LoaderConfiguration{
+		TracePath:            "test_data",
+		TraceInvocationsFile: "day1_invocations.csv",
+		TraceDurationsFile:   "day1_durations.csv",
+		TraceMemoryFile:      "day1_memory.csv",
+	}, 10).(*AzureTraceParser)
+	if parser.InvocationsFile != "day1_invocations.csv" || parser.DurationsFile != "day1_durations.csv" || parser.MemoryFile != "day1_memory.csv" {
+		t.Errorf("Configured file names have not been used - %s, %s, %s.", parser.InvocationsFile, parser.DurationsFile, parser.MemoryFile)
+	}
+}
+
+func TestCSVTraceParser(t *testing.T) {
+	parser := NewCSVTraceParser("test_data", "generic_trace.csv", map[string]string{
+		"HashOwner":            "team",
+		"HashApp":              "service",
+		"HashFunction":         "function",
+		"Invocations":          "m1",
+		"Runtime.Average":      "avg_ms",
+		"Runtime.Percentile99": "p99_ms",
+		"Memory.Average":       "mem_mb",
+	}, 3)
+	functions := parser.Parse()
+
+	if len(functions) != 2 {
+		t.Fatalf("Unexpected number of functions - got: %d, expected: 2.", len(functions))
+	}
+
+	function := functions[0]
+	if function.InvocationStats.HashOwner != "teamA" || function.InvocationStats.HashApp != "svc1" ||
+		function.InvocationStats.HashFunction != "f1" || function.InvocationStats.Trigger != "" {
+		t.Errorf("Unexpected invocation statistics - %+v.", function.InvocationStats)
+	}
+	if !slices.Equal(function.InvocationStats.Invocations, []int{1, 2, 3}) ||
+		!slices.Equal(functions[1].InvocationStats.Invocations, []int{0, 5, 0}) {
+		t.Error("Unexpected number of invocations per minute.")
+	}
+
+	runtime := function.RuntimeStats
+	if !floatEqual(runtime.Average, 100) || !floatEqual(runtime.Percentile99, 250) || !floatEqual(runtime.Percentile50, 100) ||
+		!floatEqual(runtime.Maximum, 100) || !floatEqual(runtime.Count, 1) || runtime.HashFunction != "f1" {
+		t.Errorf("Unexpected runtime statistics - %+v.", runtime)
+	}
+
+	memory := functions[1].MemoryStats
+	if !floatEqual(memory.Average, 128) || !floatEqual(memory.Percentile1, 128) || !floatEqual(memory.Percentile100, 128) ||
+		!floatEqual(memory.Count, 1) {
+		t.Errorf("Unexpected memory statistics - %+v.", memory)
+	}
+}
+
+func TestCSVTraceParserDefaultMemory(t *testing.T) {
+	functions := NewCSVTraceParser("test_data", "generic_trace.csv", map[string]string{
+		"HashFunction":    "function",
+		"Invocations":     "m2",
+		"Runtime.Average": "avg_ms",
+	}, 2).Parse()
+
+	if !slices.Equal(functions[0].InvocationStats.Invocations, []int{2, 3}) {
+		t.Error("Invocations should be read starting from the mapped column.")
+	}
+	if !floatEqual(functions[0].MemoryStats.Percentile100, DefaultTraceMemory) || !floatEqual(functions[0].MemoryStats.Average, DefaultTraceMemory) {
+		t.Errorf("Unexpected default memory statistics - %+v.", functions[0].MemoryStats)
+	}
+
+	// the specification of the functions of the trace can be generated
+	spec := generator.NewSpecificationGenerator(42).GenerateInvocationData(functions[0], common.Exponential, false, common.MinuteGranularity)
+	if len(spec.RuntimeSpecification) != 5 || spec.RuntimeSpecification[0].Runtime != 100 {
+		t.Errorf("Unexpected runtime specification - %+v.", spec.RuntimeSpecification)
+	}
+}
+
+func TestFunctionNamesIndependentOfRowOrder(t *testing.T) {
+	directory := t.TempDir()
+	reordered := "service,function,avg_ms,m1\nsvc2,f2,20,0\nsvc1,f1,100,1\n"
+	if err := os.WriteFile(filepath.Join(directory, "reordered.csv"), []byte(reordered), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	names := func(directory string, fileName string) map[string]string {
+		parser := NewCSVTraceParser(directory, fileName, map[string]string{
+			"HashFunction":    "function",
+			"Invocations":     "m1",
+			"Runtime.Average": "avg_ms",
+		}, 1)
+		parser.Seed = 42
+
+		result := make(map[string]string)
+		for _, function := range parser.Parse() {
+			result[function.InvocationStats.HashFunction] = function.Name
+		}
+
+		return result
+	}
+
+	original, other := names("test_data", "generic_trace.csv"), names(directory, "reordered.csv")
+	for _, hash := range []string{"f1", "f2"} {
+		if original[hash] == "" || original[hash] != other[hash] {
+			t.Errorf("Name of %s depends on its row - got: %s and %s.", hash, original[hash], other[hash])
+		}
+	}
+}
+
+func TestGenerateDAGsFromParsedFunctions(t *testing.T) {
+	cfg := &config.LoaderConfiguration{Width: 1, Depth: 2}
+
+	// about half of the names end with a number that does not fit into an int64
+	for seed := int64(0); seed < 8; seed++ {
+		parser := NewCSVTraceParser("test_data", "generic_trace.csv", map[string]string{
+			"HashFunction":    "function",
+			"Invocations":     "m1",
+			"Runtime.Average": "avg_ms",
+		}, 3)
+		parser.Seed = seed
+		functions := parser.Parse()
+
+		workflows := generator.GenerateDAGs(cfg, functions, false)
+		if len(workflows) != 1 || len(workflows[0].Nodes) != 2 {
+			t.Fatalf("Seed %d: unexpected DAGs - %+v.", seed, workflows)
+		}
+		if workflows[0].Nodes[0].Function.Name != functions[0].Name {
+			t.Errorf("Seed %d: unexpected root function %s.", seed, workflows[0].Nodes[0].Function.Name)
+		}
+	}
+}
+
+func TestFunctionNamesFromIdentity(t *testing.T) {
+	directory := t.TempDir()
+	shared := "service,function,avg_ms,m1\nsvc1,handler,100,1\nsvc2,handler,20,0\n"
+	if err := os.WriteFile(filepath.Join(directory, "shared.csv"), []byte(shared), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	functions := NewCSVTraceParser(directory, "shared.csv", map[string]string{
+		"HashApp":         "service",
+		"HashFunction":    "function",
+		"Invocations":     "m1",
+		"Runtime.Average": "avg_ms",
+	}, 1).Parse()
+
+	if len(functions) != 2 || functions[0].Name == functions[1].Name {
+		t.Errorf("Functions of different apps sharing their hash should have different names - %s and %s.", functions[0].Name, functions[1].Name)
+	}
+}
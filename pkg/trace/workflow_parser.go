@@ -0,0 +1,125 @@
+package trace
+
+import (
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vhive-serverless/loader/pkg/common"
+	"gopkg.in/yaml.v3"
+)
+
+type workflowFile struct {
+	Workflows []workflowDefinition `yaml:"workflows"`
+}
+
+type workflowDefinition struct {
+	Name  string                   `yaml:"name"`
+	Nodes []workflowNodeDefinition `yaml:"nodes"`
+	Edges []workflowEdgeDefinition `yaml:"edges"`
+}
+
+type workflowNodeDefinition struct {
+	Name string `yaml:"name"`
+	// Function is the HashFunction of the function in the trace or the name of the function
+	Function string `yaml:"function"`
+}
+
+type workflowEdgeDefinition struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+	// Probability of invoking the successor in (0, 1], 1 if omitted
+	Probability *float64 `yaml:"probability"`
+}
+
+// WorkflowParser reads declarative DAG workflows from a JSON or YAML file. The nodes of the workflows reference the
+// functions of the trace.
+type WorkflowParser struct {
+	path      string
+	functions []*common.Function
+}
+
+func NewWorkflowParser(path string, functions []*common.Function) *WorkflowParser {
+	return &WorkflowParser{
+		path:      path,
+		functions: functions,
+	}
+}
+
+func (wp *WorkflowParser) Parse() []*common.Workflow {
+	log.Infof("Parsing workflows: %s", wp.path)
+
+	data, err := os.ReadFile(wp.path)
+	if err != nil {
+		log.Fatalf("Failed to read the workflow file - %v", err)
+	}
+
+	// JSON is a subset of YAML, so a single decoder handles both formats
+	var definitions workflowFile
+	err = yaml.Unmarshal(data, &definitions)
+	if err != nil {
+		log.Fatalf("Failed to parse the workflow file - %v", err)
+	}
+
+	functionsByReference := make(map[string]*common.Function)
+	for _, function := range wp.functions {
+		functionsByReference[function.Name] = function
+		if function.InvocationStats != nil && function.InvocationStats.HashFunction != "" {
+			functionsByReference[function.InvocationStats.HashFunction] = function
+		}
+	}
+
+	var result []*common.Workflow
+	for _, definition := range definitions.Workflows {
+		workflow, err := createWorkflow(definition, functionsByReference)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		result = append(result, workflow)
+	}
+
+	if len(result) == 0 {
+		log.Fatalf("No workflows found in %s.", wp.path)
+	}
+
+	return result
+}
+
+func createWorkflow(definition workflowDefinition, functionsByReference map[string]*common.Function) (*common.Workflow, error) {
+	workflow := common.NewWorkflow(definition.Name)
+
+	nodesByName := make(map[string]*common.WorkflowNode)
+	for _, nodeDefinition := range definition.Nodes {
+		function, ok := functionsByReference[nodeDefinition.Function]
+		if !ok {
+			return nil, fmt.Errorf("workflow %s: function %s of node %s not found in the trace", definition.Name, nodeDefinition.Function, nodeDefinition.Name)
+		}
+		if _, ok := nodesByName[nodeDefinition.Name]; ok {
+			return nil, fmt.Errorf("workflow %s: duplicate node %s", definition.Name, nodeDefinition.Name)
+		}
+
+		nodesByName[nodeDefinition.Name] = workflow.AddNode(nodeDefinition.Name, function)
+	}
+
+	for _, edge := range definition.Edges {
+		from, okFrom := nodesByName[edge.From]
+		to, okTo := nodesByName[edge.To]
+		if !okFrom || !okTo {
+			return nil, fmt.Errorf("workflow %s: edge %s -> %s references an unknown node", definition.Name, edge.From, edge.To)
+		}
+
+		probability := 1.0
+		if edge.Probability != nil {
+			probability = *edge.Probability
+		}
+
+		workflow.AddEdge(from, to, probability)
+	}
+
+	if err := workflow.Finalize(); err != nil {
+		return nil, err
+	}
+
+	return workflow, nil
+}
@@ -0,0 +1,120 @@
+package trace
+
+import (
+	"testing"
+
+	"github.com/vhive-serverless/loader/pkg/common"
+)
+
+func createWorkflowTestFunctions() []*common.Function {
+	return []*common.Function{
+		{Name: "func-a"},
+		{Name: "func-b"},
+		{Name: "func-c"},
+		{
+			Name: "trace-func-3",
+			InvocationStats: &common.FunctionInvocationStats{
+				HashFunction: "c13acdc7567b225971cef2416a3a2b03c8a4d8d154df48afe75834e2f5c59ddf",
+			},
+		},
+	}
+}
+
+func TestWorkflowParserFromYAML(t *testing.T) {
+	functions := createWorkflowTestFunctions()
+	workflows := NewWorkflowParser("test_data/workflows.yaml", functions).Parse()
+
+	if len(workflows) != 2 {
+		t.Fatalf("Unexpected number of workflows - got %d, expected 2.", len(workflows))
+	}
+
+	diamond := workflows[0]
+	if diamond.Name != "diamond" || len(diamond.Nodes) != 4 || diamond.Root.Name != "split" {
+		t.Error("Unexpected diamond workflow.")
+	}
+
+	merge := diamond.Nodes[3]
+	if merge.Name != "merge" || len(merge.Predecessors) != 2 || merge.Depth != 2 || merge.Function != functions[0] {
+		t.Error("Join node has not been parsed correctly.")
+	}
+
+	if diamond.Root.Successors[0].Probability != 1 || diamond.Root.Successors[1].Probability != 0.5 {
+		t.Error("Unexpected edge probabilities.")
+	}
+
+	if width, depth := diamond.Shape(); width != 2 || depth != 3 {
+		t.Errorf("Unexpected shape of the workflow - width %d, depth %d.", width, depth)
+	}
+
+	if workflows[1].Root.Function != functions[3] {
+		t.Error("Function should be found by its hash.")
+	}
+}
+
+func TestWorkflowParserFromJSON(t *testing.T) {
+	functions := createWorkflowTestFunctions()
+	workflows := NewWorkflowParser("test_data/workflows.json", functions).Parse()
+
+	if len(workflows) != 1 {
+		t.Fatalf("Unexpected number of workflows - got %d, expected 1.", len(workflows))
+	}
+
+	// the root is the node without predecessors, regardless of the order of the nodes
+	chain := workflows[0]
+	if chain.Root.Name != "first" || chain.Root.Successors[0].To.Name != "second" || chain.Root.Successors[0].Probability != 0.25 {
+		t.Error("Unexpected chain workflow.")
+	}
+}
+
+func TestWorkflowValidation(t *testing.T) {
+	functions := createWorkflowTestFunctions()
+
+	cycle := common.NewWorkflow("cycle")
+	a := cycle.AddNode("a", functions[0])
+	b := cycle.AddNode("b", functions[1])
+	c := cycle.AddNode("c", functions[2])
+	cycle.AddEdge(a, b, 1)
+	cycle.AddEdge(b, c, 1)
+	cycle.AddEdge(c, b, 1)
+	if cycle.Finalize() == nil {
+		t.Error("Cycle has not been detected.")
+	}
+
+	twoRoots := common.NewWorkflow("two_roots")
+	twoRoots.AddNode("a", functions[0])
+	twoRoots.AddNode("b", functions[1])
+	if twoRoots.Finalize() == nil {
+		t.Error("Workflow with two roots has not been detected.")
+	}
+
+	invalidProbability := common.NewWorkflow("invalid_probability")
+	invalidProbability.AddEdge(invalidProbability.AddNode("a", functions[0]), invalidProbability.AddNode("b", functions[1]), 1.5)
+	if invalidProbability.Finalize() == nil {
+		t.Error("Invalid probability has not been detected.")
+	}
+}
+
+func TestWorkflowEdgeProbability(t *testing.T) {
+	functionsByReference := make(map[string]*common.Function)
+	for _, function := range createWorkflowTestFunctions() {
+		functionsByReference[function.Name] = function
+	}
+
+	definition := func(probability *float64) workflowDefinition {
+		return workflowDefinition{
+			Name:  "edge",
+			Nodes: []workflowNodeDefinition{{Name: "a", Function: "func-a"}, {Name: "b", Function: "func-b"}},
+			Edges: []workflowEdgeDefinition{{From: "a", To: "b", Probability: probability}},
+		}
+	}
+
+	workflow, err := createWorkflow(definition(nil), functionsByReference)
+	if err != nil || workflow.Root.Successors[0].Probability != 1 {
+		t.Errorf("Omitted probability should default to 1 - %v.", err)
+	}
+
+	zero := 0.0
+	if _, err = createWorkflow(definition(&zero), functionsByReference); err == nil {
+		t.Error("Explicit probability of 0 should be rejected.")
+	}
+}
@@ -25,8 +25,10 @@
 package main
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	util "github.com/vhive-serverless/loader/pkg/common"
+	"github.com/vhive-serverless/loader/pkg/workload/proto"
 	"strconv"
 	"time"
 )
@@ -36,6 +38,26 @@ type FunctionResponse struct {
 	Function      string `json:"Function"`
 	MachineName   string `json:"MachineName"`
 	ExecutionTime int64  `json:"ExecutionTime"`
+
+	Payload         []byte `json:"Payload,omitempty"`
+	PayloadChecksum uint32 `json:"PayloadChecksum,omitempty"`
+	RequestChecksum uint32 `json:"RequestChecksum,omitempty"`
+}
+
+// payloadRequest reads the payload the loader has sent base64-encoded in the payload parameter and the size of the
+// response it has asked for in the response_size parameter
+func payloadRequest(obj map[string]interface{}) *proto.FaasRequest {
+	req := &proto.FaasRequest{}
+
+	if encoded, ok := obj["payload"].(string); ok {
+		req.Payload, _ = base64.StdEncoding.DecodeString(encoded)
+	}
+	if responseSize, ok := obj["response_size"].(string); ok {
+		size, _ := strconv.Atoi(responseSize)
+		req.ResponseSizeInBytes = uint32(size)
+	}
+
+	return req
 }
 
 func Main(obj map[string]interface{}) map[string]interface{} {
@@ -54,12 +76,17 @@ func Main(obj map[string]interface{}) map[string]interface{} {
 
 	util.TraceFunctionExecution(start, uint32(155), timeLeftMilliseconds)
 
-	responseBytes, _ := json.Marshal(FunctionResponse{
+	response := FunctionResponse{
 		Status:        "OK",
 		Function:      "",
 		MachineName:   "NYI",
 		ExecutionTime: time.Since(start).Microseconds(),
-	})
+	}
+	if req := payloadRequest(obj); len(req.GetPayload()) > 0 || req.GetResponseSizeInBytes() > 0 {
+		response.Payload, response.PayloadChecksum, response.RequestChecksum = proto.PayloadReply(req)
+	}
+
+	responseBytes, _ := json.Marshal(response)
 
 	result["body"] = responseBytes
 
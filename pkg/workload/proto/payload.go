@@ -0,0 +1,17 @@
+package proto
+
+import "hash/crc32"
+
+// PayloadReply returns the payload of the reply to a request together with the checksums the client validates. The
+// request payload is echoed unless the request asks for a response of a given size.
+func PayloadReply(req *FaasRequest) (payload []byte, payloadChecksum uint32, requestChecksum uint32) {
+	payload = req.GetPayload()
+	if size := req.GetResponseSizeInBytes(); size > 0 {
+		payload = make([]byte, size)
+		for i := range payload {
+			payload[i] = byte(i)
+		}
+	}
+
+	return payload, crc32.ChecksumIEEE(payload), crc32.ChecksumIEEE(req.GetPayload())
+}
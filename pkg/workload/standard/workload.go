@@ -81,10 +81,15 @@ func (s *funcServer) Execute(_ context.Context, req *proto.FaasRequest) (*proto.
 		msg = fmt.Sprintf("OK - EMPTY - %s", hostname)
 	}
 
+	payload, payloadChecksum, requestChecksum := proto.PayloadReply(req)
+
 	return &proto.FaasReply{
 		Message:            msg,
 		DurationInMicroSec: uint32(time.Since(start).Microseconds()),
 		MemoryUsageInKb:    req.MemoryInMebiBytes * 1024,
+		Payload:            payload,
+		PayloadChecksum:    payloadChecksum,
+		RequestChecksum:    requestChecksum,
 	}, nil
 }
 
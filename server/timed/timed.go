@@ -94,10 +94,15 @@ finish:
 		msg = "Timeout when materialising allocated memory."
 	}
 
+	payload, payloadChecksum, requestChecksum := proto.PayloadReply(req)
+
 	return &proto.FaasReply{
 		Message:            msg,
 		DurationInMicroSec: uint32(time.Since(start).Microseconds()),
 		MemoryUsageInKb:    util.B2Kib(numPagesRequested * uint32(unix.Getpagesize())),
+		Payload:            payload,
+		PayloadChecksum:    payloadChecksum,
+		RequestChecksum:    requestChecksum,
 	}, nil
 }
 